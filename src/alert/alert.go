@@ -108,17 +108,17 @@ func addPrefix(prefix string, msgs ...interface{}) []interface{} {
 }
 
 // Returns the config for the given path, else nil and then also returns true if found
-func getCfg(cfgPath string, cfg *cfg.Config) (*cfg.Config, bool) {
+func getCfg(cfgPath string, config *cfg.Config) (*cfg.Config, bool) {
 
 	// If path has a '.Use', ...
-	if cfg.Has("Alert." + cfgPath + ".Use") {
+	if config.Has("Alert." + cfgPath + ".Use") {
 
-		// ...and Use is True...
-		use := cfg.Get("Alert." + cfgPath + ".Use")
-		if use == "true" || use == "True" {
+		// ...and Use is True (an invalid value leaves it off)...
+		use, err := cfg.ParseB(config.Get("Alert." + cfgPath + ".Use"))
+		if err == nil && use {
 
 			// ...Get Alert configuration for path
-			return cfg.Descend("Alert." + cfgPath), true
+			return config.Descend("Alert." + cfgPath), true
 		}
 	}
 	return nil, false
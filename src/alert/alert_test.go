@@ -1,8 +1,11 @@
 package alert
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 
+	"github.com/enova/tokyo/src/cfg"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -145,3 +148,25 @@ func TestHandler(t *testing.T) {
 	assert.Contains(handler.msg.Text, "xyz")
 	assert.Equal(len(handler.msg.Flags), 1)
 }
+
+func TestGetCfg(t *testing.T) {
+	assert := assert.New(t)
+
+	file, err := ioutil.TempFile("", "alert")
+	assert.Nil(err)
+	defer os.Remove(file.Name())
+	file.WriteString("Alert.Sentry.Use yes\nAlert.Sentry.DSN x\nAlert.LogFile.Use maybe\n")
+	file.Close()
+
+	config := cfg.New(file.Name())
+
+	_, ok := getCfg("Sentry", config)
+	assert.True(ok)
+
+	// Invalid Values Leave It Off
+	_, ok = getCfg("LogFile", config)
+	assert.False(ok)
+
+	_, ok = getCfg("Multicast", config)
+	assert.False(ok)
+}
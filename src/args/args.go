@@ -3,7 +3,6 @@ package args
 import (
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 )
 
@@ -15,10 +14,12 @@ type keyVal struct {
 
 // Args contains arguments and their derivatives
 type Args struct {
-	raws    []string // Raw Arguments
-	vals    []string // Non-Optional Arguments
-	uniOpts []string // Unary Flags: -Flag
-	binOpts []keyVal // Binary Flags: -Key=Value
+	raws    []string          // Raw Arguments
+	vals    []string          // Non-Optional Arguments
+	uniOpts []string          // Unary Flags: -Flag
+	binOpts []keyVal          // Binary Flags: -Key=Value
+	spec    *Spec             // Expected options (nil if parsed without a spec)
+	env     map[string]string // Option name => environment variable (for values taken from it)
 }
 
 // Parse returns a newly created Args using os.Args
@@ -37,6 +38,12 @@ func New(raws []string) *Args {
 	for i := 0; i < len(raws); i++ {
 		raw := raws[i]
 
+		// End Of Options: -- (The Remaining Arguments Are Non-Options)
+		if raw == "--" && i > 0 {
+			a.vals = append(a.vals, raws[i+1:]...)
+			break
+		}
+
 		if strings.HasPrefix(raw, "-") {
 
 			// Option
@@ -64,6 +71,21 @@ func New(raws []string) *Args {
 	return &a
 }
 
+// Command returns the subcommand chosen when parsing with a spec that has
+// subcommands, e.g. "fetch" (or "remote add" for nested subcommands). It
+// returns an empty string if there is none.
+func (a *Args) Command() string {
+	if a.spec == nil || a.spec.parent == nil {
+		return ""
+	}
+
+	var names []string
+	for s := a.spec; s.parent != nil; s = s.parent {
+		names = append([]string{s.Name}, names...)
+	}
+	return strings.Join(names, " ")
+}
+
 // Raws returns the list of raw arguments
 func (a *Args) Raws() []string {
 	return a.raws
@@ -79,16 +101,24 @@ func (a *Args) Size() int {
 // your application to die in case of an out-of-range index, use the
 // method Size().
 func (a *Args) Get(i int) string {
+	val, err := a.Lookup(i)
+	exitOn(err)
+	return val
+}
+
+// Lookup returns the non-optional argument in the supplied position, or
+// an error if the index is out of range
+func (a *Args) Lookup(i int) (string, error) {
 	if i < 0 || i >= a.Size() {
-		fmt.Fprintf(os.Stderr, "Args: Index out of range: %d  [0, %d)\n", i, a.Size())
-		os.Exit(1)
+		return "", fmt.Errorf("Args: Index out of range: %d  [0, %d)", i, a.Size())
 	}
 
-	return a.vals[i]
+	return a.vals[i], nil
 }
 
 // IsOn returns true if the supplied unary argument is present
 func (a *Args) IsOn(s string) bool {
+	s = a.name(s)
 	for _, u := range a.uniOpts {
 		if u == s {
 			return true
@@ -104,6 +134,7 @@ func (a *Args) IsOff(s string) bool {
 
 // HasOpt return true if the supplied binary option is present
 func (a *Args) HasOpt(key string) bool {
+	key = a.name(key)
 	for _, b := range a.binOpts {
 		if b.key == key {
 			return true
@@ -112,35 +143,86 @@ func (a *Args) HasOpt(key string) bool {
 	return false
 }
 
+// OptKeys returns the keys of all binary options (in the order they
+// first occur on the command line)
+func (a *Args) OptKeys() []string {
+	var result []string
+	seen := make(map[string]bool)
+
+	for _, b := range a.binOpts {
+		if !seen[b.key] {
+			result = append(result, b.key)
+			seen[b.key] = true
+		}
+	}
+
+	return result
+}
+
 // GetOpt return the value corresponding to the supplied key. It
 // exits if the option is not found (Exit(1)). If you don't want
 // your application to die in case of a missing option, then use
 // the method HasOpt() to check whether an option has been supplied.
+// If the args were parsed using a spec, a missing option returns
+// its default (if it has one).
 func (a *Args) GetOpt(key string) string {
+	val, err := a.LookupOpt(key)
+	exitOn(err)
+	return val
+}
+
+// LookupOpt returns the value corresponding to the supplied key (as
+// GetOpt does), or an error if the option is not found
+func (a *Args) LookupOpt(key string) (string, error) {
+	key = a.name(key)
 	for _, b := range a.binOpts {
 		if b.key == key {
-			return b.val
+			return b.val, nil
+		}
+	}
+
+	// Default
+	if a.spec != nil {
+		if o := a.spec.find(key); o != nil && o.Default != "" {
+			return o.Default, nil
 		}
 	}
 
-	// Invalid Option
-	fmt.Fprintf(os.Stderr, "Args - Missing option: %s\n", key)
-	os.Exit(1)
+	return "", fmt.Errorf("Args - Missing option: %s", key)
+}
 
-	// Never Gets Here
-	return ""
+// GetOptOr returns the value corresponding to the supplied key, or the
+// supplied default if the option is not found
+func (a *Args) GetOptOr(key, def string) string {
+	val, err := a.LookupOpt(key)
+	if err != nil {
+		return def
+	}
+	return val
 }
 
 // GetOptI return the value corresponding to the supplied key as an
 // integer (int). It exits if the option is not found (Exit(1))
 func (a *Args) GetOptI(key string) int {
-	s := a.GetOpt(key)
+	i, err := a.LookupOptI(key)
+	exitOn(err)
+	return i
+}
+
+// Returns the option's name if the supplied key is one of its aliases
+func (a *Args) name(key string) string {
+	if a.spec != nil {
+		if o := a.spec.find(key); o != nil {
+			return o.Name
+		}
+	}
+	return key
+}
 
-	i, err := strconv.Atoi(s)
+// Prints the error and exits(1) (if there is an error)
+func exitOn(err error) {
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Args: Invalid option value - %s for key %s - Must be an integer", s, key)
+		fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(1)
 	}
-
-	return i
 }
@@ -31,4 +31,14 @@ func TestArgs(t *testing.T) {
 	assert.True(a.HasOpt("a"))
 	assert.Equal(a.GetOpt("a"), "5")
 	assert.Equal(a.GetOptI("a"), 5)
+
+	// Binary Option Keys
+	a = NewArgs("-x=1", "b", "-y=2", "-x=3", "-z")
+	assert.Equal([]string{"x", "y"}, a.OptKeys())
+
+	// End Of Options
+	a = NewArgs("prog", "-d", "--", "-5", "-x=1")
+	assert.True(a.IsOn("d"))
+	assert.Equal([]string{"prog", "-5", "-x=1"}, a.vals)
+	assert.False(a.HasOpt("x"))
 }
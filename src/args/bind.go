@@ -0,0 +1,287 @@
+package args
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Binding is a struct whose fields are filled after parsing
+type binding struct {
+	target reflect.Value // The struct
+	fields []field
+}
+
+// Field is a single tagged field of a bound struct
+type field struct {
+	index int    // Position within the struct
+	name  string // Option name (empty for non-option arguments)
+	pos   int    // Position of the non-option argument
+	rest  bool   // Takes the non-option arguments from pos onwards
+}
+
+// Bind parses os.Args into the struct pointed to by v (see Spec.Bind).
+// The help text is generated from the struct's tags. It exits under the
+// same conditions as Spec.New.
+func Bind(v interface{}) *Args {
+	return NewSpec(filepath.Base(os.Args[0])).Bind(v).Parse()
+}
+
+// Bind adds an option for each field of the struct pointed to by v that has
+// an arg tag. After parsing (with New, Parse or Load) the fields are set
+// from the options' values, or from non-option arguments if the tag is a
+// position ("1" for Get(1), "2..." for Get(2) onwards):
+//
+//	type options struct {
+//		Verbose bool          `arg:"v,verbose" help:"Print details"`
+//		Skip    int           `arg:"s" default:"0" implied:"1" placeholder:"N" help:"Skip first N lines"`
+//		Wait    time.Duration `arg:"wait" required:"true" help:"Time to wait"`
+//		Tags    []string      `arg:"tag" help:"Tag (repeatable)"`
+//		Files   []string      `arg:"1..." placeholder:"FILE"`
+//	}
+//
+// The first name in the arg tag is the option's name, the rest are its
+// aliases, and an env tag names its environment variable (see Opt.Env).
+// A choices tag lists the allowed values (e.g. choices:"csv,tsv") and a
+// hint tag tells shell completion what the value (or, on a non-option
+// field, the argument) is: hint:"file" or hint:"dir".
+// Bools are flags, slices collect every occurrence of an option, and ints,
+// floats, strings and time.Durations take a single value. If the spec has
+// no usage, one is generated from the non-option fields. It exits(1) if v
+// is not a pointer to a struct or a tagged field is unexported or has an
+// unsupported type.
+func (s *Spec) Bind(v interface{}) *Spec {
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Ptr || ptr.Elem().Kind() != reflect.Struct {
+		fmt.Fprintf(os.Stderr, "Args - Bind requires a pointer to a struct, not %T\n", v)
+		os.Exit(1)
+	}
+
+	b := binding{target: ptr.Elem()}
+	var positional []string
+
+	t := b.target.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("arg")
+		if !ok {
+			continue
+		}
+
+		typ, ok := typeOf(sf.Type)
+		if !ok || sf.PkgPath != "" {
+			fmt.Fprintf(os.Stderr, "Args - Bind can't set field %s (of type %s)\n", sf.Name, sf.Type)
+			os.Exit(1)
+		}
+
+		placeholder := sf.Tag.Get("placeholder")
+		if placeholder == "" {
+			placeholder = strings.ToUpper(sf.Name)
+		}
+
+		// Non-Option Argument: "1" Or "1..."
+		f := field{index: i}
+		if n, err := strconv.Atoi(strings.TrimSuffix(tag, "...")); err == nil {
+			f.pos = n
+			f.rest = strings.HasSuffix(tag, "...")
+			if hint := Hint(sf.Tag.Get("hint")); hint != HintNone {
+				s.ArgHint = hint
+			}
+			if f.rest {
+				positional = append(positional, placeholder+"...")
+			} else {
+				positional = append(positional, placeholder)
+			}
+			b.fields = append(b.fields, f)
+			continue
+		}
+
+		// Option
+		names := strings.Split(tag, ",")
+		f.name = names[0]
+		s.Add(Opt{
+			Name:        names[0],
+			Aliases:     names[1:],
+			Type:        typ,
+			Default:     sf.Tag.Get("default"),
+			Implied:     sf.Tag.Get("implied"),
+			Placeholder: sf.Tag.Get("placeholder"),
+			Help:        sf.Tag.Get("help"),
+			Required:    sf.Tag.Get("required") == "true",
+			Env:         sf.Tag.Get("env"),
+			Choices:     choices(sf.Tag.Get("choices")),
+			Hint:        Hint(sf.Tag.Get("hint")),
+		})
+		b.fields = append(b.fields, f)
+	}
+
+	// Usage (From The Non-Option Fields)
+	if s.Usage == "" {
+		s.Usage = strings.Join(append([]string{s.path(), "[options]"}, positional...), " ")
+	}
+
+	s.bindings = append(s.bindings, b)
+	return s
+}
+
+// Sets the fields of the bindings of the spec (and the specs it inherits from)
+func (a *Args) fill() error {
+	for s := a.spec; s != nil; s = s.parent {
+		for _, b := range s.bindings {
+			for _, f := range b.fields {
+				if err := a.fillField(b.target.Field(f.index), f); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Sets a single field (missing options and arguments are left unchanged)
+func (a *Args) fillField(v reflect.Value, f field) error {
+	var vals []string
+
+	switch {
+
+	// Non-Option Arguments
+	case f.name == "" && f.rest:
+		if f.pos < a.Size() {
+			vals = a.vals[f.pos:]
+		}
+	case f.name == "":
+		if f.pos < a.Size() {
+			vals = []string{a.vals[f.pos]}
+		}
+
+	// Flag
+	case v.Kind() == reflect.Bool:
+		if a.IsOn(f.name) {
+			v.SetBool(true)
+		}
+		return nil
+
+	// Option (All Occurrences)
+	default:
+		vals, _ = a.LookupOptList(f.name)
+	}
+
+	if len(vals) == 0 {
+		return nil
+	}
+
+	// Slice
+	if v.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(v.Type(), len(vals), len(vals))
+		for i, s := range vals {
+			if err := setValue(slice.Index(i), s); err != nil {
+				return fmt.Errorf("Args - Invalid value for %s: %s (%s)", describe(f), s, err.Error())
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+
+	if err := setValue(v, vals[0]); err != nil {
+		return fmt.Errorf("Args - Invalid value for %s: %s (%s)", describe(f), vals[0], err.Error())
+	}
+	return nil
+}
+
+// Sets a value of a supported type from a string
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return errors.New("must be a duration")
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+
+	case reflect.String:
+		v.SetString(s)
+
+	case reflect.Bool:
+		b, err := ParseB(s)
+		if err != nil {
+			return errors.New("must be a boolean")
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		v.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be a non-negative integer")
+		}
+		v.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		v.SetFloat(f)
+	}
+
+	return nil
+}
+
+// Returns the option type for a field type (false if unsupported)
+func typeOf(t reflect.Type) (Type, bool) {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return TypeDuration, true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return TypeFlag, true
+	case reflect.String:
+		return TypeString, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return TypeInt, true
+	case reflect.Float32, reflect.Float64:
+		return TypeFloat, true
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Slice {
+			return "", false
+		}
+		if t.Elem().Kind() == reflect.Bool {
+			return TypeBool, true
+		}
+		return typeOf(t.Elem())
+	}
+
+	return "", false
+}
+
+// Splits the choices tag (nil if empty)
+func choices(tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	return strings.Split(tag, ",")
+}
+
+// Describes the field for error messages, e.g. "option -s" or "argument 1"
+func describe(f field) string {
+	if f.name != "" {
+		return "option -" + f.name
+	}
+	return "argument " + strconv.Itoa(f.pos)
+}
@@ -0,0 +1,98 @@
+package args
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type testOptions struct {
+	Verbose bool          `arg:"v,verbose" help:"Print details"`
+	Skip    int           `arg:"s" default:"0" implied:"1" placeholder:"N" help:"Skip first N lines"`
+	Ratio   float64       `arg:"ratio" default:"0.5" help:"Ratio"`
+	Size    uint8         `arg:"size" help:"Size"`
+	Name    string        `arg:"name" help:"Name"`
+	Wait    time.Duration `arg:"wait" required:"true" help:"Time to wait"`
+	Tags    []string      `arg:"tag" help:"Tag (repeatable)"`
+	Ports   []int         `arg:"port" help:"Port (repeatable)"`
+	Mode    string        `arg:"1"`
+	Files   []string      `arg:"2..." placeholder:"FILE"`
+	Ignored string
+}
+
+func TestBind(t *testing.T) {
+	assert := assert.New(t)
+
+	var opts testOptions
+	opts.Name = "unchanged"
+	s := NewSpec("prog").Bind(&opts)
+
+	_, err := s.Load([]string{"prog", "copy", "-v", "-s", "-wait=1m", "-tag=a", "-port", "80", "a.txt", "-tag=b", "-port=443", "b.txt", "-size=7"})
+	assert.Nil(err)
+	assert.Equal(testOptions{
+		Verbose: true,
+		Skip:    1,
+		Ratio:   0.5,
+		Size:    7,
+		Name:    "unchanged",
+		Wait:    time.Minute,
+		Tags:    []string{"a", "b"},
+		Ports:   []int{80, 443},
+		Mode:    "copy",
+		Files:   []string{"a.txt", "b.txt"},
+	}, opts)
+
+	// Errors
+	for _, raws := range [][]string{
+		{"prog"},                          // Missing required option
+		{"prog", "-wait=1m", "-size=300"}, // Out of range
+		{"prog", "-wait=1m", "-size=-1"},  // Negative
+		{"prog", "-wait=1m", "-port=x"},   // Not an integer
+	} {
+		_, err := s.Load(raws)
+		assert.NotNil(err, raws)
+	}
+
+	_, err = s.Load([]string{"prog", "-wait=1m", "-size=300"})
+	assert.EqualError(err, "Args - Invalid value for option -size: 300 (must be a non-negative integer)")
+
+	// Help (From The Tags)
+	assert.Equal(`
+prog
+----
+
+prog [options] MODE FILE...
+
+-h, -help       Help
+-v, -verbose    Print details
+-s[=N]          Skip first N lines (default: 0)
+-ratio=FLOAT    Ratio (default: 0.5)
+-size=INT       Size
+-name=STRING    Name
+-wait=DURATION  Time to wait (required)
+-tag=STRING     Tag (repeatable)
+-port=INT       Port (repeatable)
+
+`, s.Help())
+}
+
+func TestBindCommands(t *testing.T) {
+	assert := assert.New(t)
+
+	var global struct {
+		Verbose bool `arg:"v"`
+	}
+	var fetch struct {
+		Force  bool   `arg:"force"`
+		Remote string `arg:"1"`
+	}
+
+	s := NewSpec("tool").Bind(&global)
+	s.Command("fetch", nil).Bind(&fetch)
+
+	_, err := s.Load([]string{"tool", "fetch", "origin", "-v", "-force"})
+	assert.Nil(err)
+	assert.True(global.Verbose)
+	assert.True(fetch.Force)
+	assert.Equal("origin", fetch.Remote)
+}
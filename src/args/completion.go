@@ -0,0 +1,401 @@
+package args
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Hint tells shell completion what a value is
+type Hint string
+
+// Hints
+const (
+	HintNone Hint = ""     // Anything (nothing is offered)
+	HintFile Hint = "file" // A file name
+	HintDir  Hint = "dir"  // A directory name
+)
+
+// Shells
+var shells = []string{"bash", "zsh", "fish"}
+
+// Characters Not Allowed In Shell Function Names
+var unsafePattern = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// AddCompletion adds the option -completion=bash|zsh|fish, which prints
+// a completion script for the spec and exits(0) (see Completion). It must
+// be called on the top-level spec:
+//
+//	source <(cols -completion=bash)
+func (s *Spec) AddCompletion() *Spec {
+	s.Add(Opt{Name: "completion", Type: TypeString, Choices: shells, Help: "Print a shell completion script", noEnv: true})
+	s.completion = true
+	return s
+}
+
+// Completion returns a completion script for the supplied shell (bash,
+// zsh or fish), or an error for any other shell. The script completes
+// the options and subcommands, the choices of an option (see Opt.Choices),
+// and files or directories where the spec hints at them (see Opt.Hint and
+// Spec.ArgHint).
+func (s *Spec) Completion(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return s.bash(), nil
+	case "zsh":
+		return s.zsh(), nil
+	case "fish":
+		return s.fish(), nil
+	}
+
+	return "", fmt.Errorf("Args - Unknown shell: %s (must be one of %s)", shell, strings.Join(shells, ", "))
+}
+
+// Returns the bash completion script
+func (s *Spec) bash() string {
+	var b bytes.Buffer
+	fn := function(s.Name)
+
+	fmt.Fprintf(&b, "# bash completion for %s (generated by %s -completion=bash)\n", s.Name, s.Name)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	fmt.Fprintf(&b, "    local cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprintf(&b, "    local cmd=\"\" opt=\"\" words=\"\" cmds=\"\" args=\"\" i\n")
+	s.bashCommand(&b)
+
+	fmt.Fprintf(&b, "\n    # Value After =: -opt=VALUE\n")
+	fmt.Fprintf(&b, "    if [[ $cur == \"=\" ]]; then\n")
+	fmt.Fprintf(&b, "        opt=\"$prev\"\n")
+	fmt.Fprintf(&b, "        cur=\"\"\n")
+	fmt.Fprintf(&b, "    elif [[ $prev == \"=\" ]]; then\n")
+	fmt.Fprintf(&b, "        opt=\"${COMP_WORDS[COMP_CWORD-2]}\"\n")
+	fmt.Fprintf(&b, "    fi\n\n")
+
+	fmt.Fprintf(&b, "    case \"$cmd\" in\n")
+	for _, spec := range s.specs() {
+		opts := spec.all()
+		fmt.Fprintf(&b, "    %q)\n", spec.key())
+
+		// Value After A Space
+		if spaced := patterns(opts, takesSpace); spaced != "" {
+			fmt.Fprintf(&b, "        # Value After A Space: -opt VALUE\n")
+			fmt.Fprintf(&b, "        if [[ -z $opt ]]; then\n")
+			fmt.Fprintf(&b, "            case \"$prev\" in\n")
+			fmt.Fprintf(&b, "            %s) opt=\"$prev\" ;;\n", spaced)
+			fmt.Fprintf(&b, "            esac\n")
+			fmt.Fprintf(&b, "        fi\n\n")
+		}
+
+		// Values
+		fmt.Fprintf(&b, "        case \"$opt\" in\n")
+		for _, o := range opts {
+			switch {
+			case len(o.Choices) > 0:
+				fmt.Fprintf(&b, "        %s) COMPREPLY=($(compgen -W %q -- \"$cur\")); return ;;\n", pattern(o), strings.Join(o.Choices, " "))
+			case o.Hint == HintFile:
+				fmt.Fprintf(&b, "        %s) COMPREPLY=($(compgen -f -- \"$cur\")); return ;;\n", pattern(o))
+			case o.Hint == HintDir:
+				fmt.Fprintf(&b, "        %s) COMPREPLY=($(compgen -d -- \"$cur\")); return ;;\n", pattern(o))
+			}
+		}
+		fmt.Fprintf(&b, "        ?*) return ;;\n")
+		fmt.Fprintf(&b, "        esac\n\n")
+
+		// Options, Subcommands And Arguments
+		var words []string
+		for _, o := range opts {
+			words = append(words, completions(o)...)
+		}
+		fmt.Fprintf(&b, "        words=%q\n", strings.Join(words, " "))
+		if len(spec.commands) > 0 {
+			fmt.Fprintf(&b, "        cmds=%q\n", strings.Join(spec.commandNames(), " "))
+		}
+		if spec.ArgHint != HintNone {
+			fmt.Fprintf(&b, "        args=%q\n", spec.ArgHint)
+		}
+		fmt.Fprintf(&b, "        ;;\n")
+	}
+	fmt.Fprintf(&b, "    esac\n\n")
+
+	fmt.Fprintf(&b, "    if [[ $cur == -* ]]; then\n")
+	fmt.Fprintf(&b, "        COMPREPLY=($(compgen -W \"$words\" -- \"$cur\"))\n")
+	fmt.Fprintf(&b, "        [[ ${#COMPREPLY[@]} == 1 && ${COMPREPLY[0]} == *= ]] && compopt -o nospace\n")
+	fmt.Fprintf(&b, "    elif [[ -n $cmds ]]; then\n")
+	fmt.Fprintf(&b, "        COMPREPLY=($(compgen -W \"$cmds\" -- \"$cur\"))\n")
+	fmt.Fprintf(&b, "    elif [[ $args == file ]]; then\n")
+	fmt.Fprintf(&b, "        COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+	fmt.Fprintf(&b, "    elif [[ $args == dir ]]; then\n")
+	fmt.Fprintf(&b, "        COMPREPLY=($(compgen -d -- \"$cur\"))\n")
+	fmt.Fprintf(&b, "    fi\n")
+	fmt.Fprintf(&b, "}\n\n")
+	fmt.Fprintf(&b, "complete -F %s %s\n", fn, s.Name)
+
+	return b.String()
+}
+
+// Writes the bash (or zsh) loop finding the subcommand, e.g. " remote add"
+func (s *Spec) bashCommand(b *bytes.Buffer) {
+	if len(s.commands) == 0 {
+		return
+	}
+
+	var keys []string
+	for _, spec := range s.specs()[1:] {
+		keys = append(keys, fmt.Sprintf("%q", spec.key()))
+	}
+
+	fmt.Fprintf(b, "\n    # Subcommand\n")
+	fmt.Fprintf(b, "    for ((i = 1; i < COMP_CWORD; i++)); do\n")
+	fmt.Fprintf(b, "        case \"$cmd ${COMP_WORDS[i]}\" in\n")
+	fmt.Fprintf(b, "        %s) cmd=\"$cmd ${COMP_WORDS[i]}\" ;;\n", strings.Join(keys, "|"))
+	fmt.Fprintf(b, "        esac\n")
+	fmt.Fprintf(b, "    done\n")
+}
+
+// Returns the zsh completion script
+func (s *Spec) zsh() string {
+	var b bytes.Buffer
+	fn := function(s.Name)
+
+	fmt.Fprintf(&b, "#compdef %s\n", s.Name)
+	fmt.Fprintf(&b, "# zsh completion for %s (generated by %s -completion=zsh)\n\n", s.Name, s.Name)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	fmt.Fprintf(&b, "    local cur=\"${words[CURRENT]}\" prev=\"${words[CURRENT-1]}\" cmd=\"\" opt=\"\" args=\"\" i\n")
+	fmt.Fprintf(&b, "    local -a flags valued cmds\n")
+
+	// Same Loop As Bash (Over The Words Of Zsh)
+	var loop bytes.Buffer
+	s.bashCommand(&loop)
+	text := strings.Replace(loop.String(), "i = 1; i < COMP_CWORD", "i = 2; i < CURRENT", 1)
+	b.WriteString(strings.Replace(text, "COMP_WORDS[i]", "words[i]", -1))
+
+	fmt.Fprintf(&b, "\n    # Value After =: -opt=VALUE\n")
+	fmt.Fprintf(&b, "    if [[ $cur == -*=* ]]; then\n")
+	fmt.Fprintf(&b, "        opt=\"${cur%%%%=*}\"\n")
+	fmt.Fprintf(&b, "        compset -P '*='\n")
+	fmt.Fprintf(&b, "    fi\n\n")
+
+	fmt.Fprintf(&b, "    case \"$cmd\" in\n")
+	for _, spec := range s.specs() {
+		opts := spec.all()
+		fmt.Fprintf(&b, "    %q)\n", spec.key())
+
+		// Value After A Space
+		if spaced := patterns(opts, takesSpace); spaced != "" {
+			fmt.Fprintf(&b, "        # Value After A Space: -opt VALUE\n")
+			fmt.Fprintf(&b, "        if [[ -z $opt ]]; then\n")
+			fmt.Fprintf(&b, "            case \"$prev\" in\n")
+			fmt.Fprintf(&b, "            %s) opt=\"$prev\" ;;\n", spaced)
+			fmt.Fprintf(&b, "            esac\n")
+			fmt.Fprintf(&b, "        fi\n\n")
+		}
+
+		// Values
+		fmt.Fprintf(&b, "        case \"$opt\" in\n")
+		for _, o := range opts {
+			switch {
+			case len(o.Choices) > 0:
+				fmt.Fprintf(&b, "        %s) compadd -- %s; return ;;\n", pattern(o), quoteAll(o.Choices))
+			case o.Hint == HintFile:
+				fmt.Fprintf(&b, "        %s) _files; return ;;\n", pattern(o))
+			case o.Hint == HintDir:
+				fmt.Fprintf(&b, "        %s) _files -/; return ;;\n", pattern(o))
+			}
+		}
+		fmt.Fprintf(&b, "        ?*) return ;;\n")
+		fmt.Fprintf(&b, "        esac\n\n")
+
+		// Options (With Descriptions), Subcommands And Arguments
+		var flags, valued []string
+		for _, o := range opts {
+			for _, word := range completions(o) {
+				if strings.HasSuffix(word, "=") {
+					valued = append(valued, quote(word+":"+o.Help))
+				} else {
+					flags = append(flags, quote(word+":"+o.Help))
+				}
+			}
+		}
+		fmt.Fprintf(&b, "        flags=(%s)\n", strings.Join(flags, " "))
+		fmt.Fprintf(&b, "        valued=(%s)\n", strings.Join(valued, " "))
+		if len(spec.commands) > 0 {
+			var cmds []string
+			for _, c := range spec.commands {
+				cmds = append(cmds, quote(c.Name+":"+c.Summary))
+			}
+			fmt.Fprintf(&b, "        cmds=(%s)\n", strings.Join(cmds, " "))
+		}
+		if spec.ArgHint != HintNone {
+			fmt.Fprintf(&b, "        args=%q\n", spec.ArgHint)
+		}
+		fmt.Fprintf(&b, "        ;;\n")
+	}
+	fmt.Fprintf(&b, "    esac\n\n")
+
+	fmt.Fprintf(&b, "    if [[ $cur == -* ]]; then\n")
+	fmt.Fprintf(&b, "        _describe option flags -- valued -S ''\n")
+	fmt.Fprintf(&b, "    elif (( ${#cmds} )); then\n")
+	fmt.Fprintf(&b, "        _describe command cmds\n")
+	fmt.Fprintf(&b, "    elif [[ $args == file ]]; then\n")
+	fmt.Fprintf(&b, "        _files\n")
+	fmt.Fprintf(&b, "    elif [[ $args == dir ]]; then\n")
+	fmt.Fprintf(&b, "        _files -/\n")
+	fmt.Fprintf(&b, "    fi\n")
+	fmt.Fprintf(&b, "}\n\n")
+
+	// Autoloaded (From fpath) Or Sourced
+	fmt.Fprintf(&b, "if [[ $funcstack[1] == %s ]]; then\n", fn)
+	fmt.Fprintf(&b, "    %s \"$@\"\n", fn)
+	fmt.Fprintf(&b, "else\n")
+	fmt.Fprintf(&b, "    compdef %s %s\n", fn, s.Name)
+	fmt.Fprintf(&b, "fi\n")
+
+	return b.String()
+}
+
+// Returns the fish completion script
+func (s *Spec) fish() string {
+	var b bytes.Buffer
+	fn := "_" + function(s.Name) + "_command"
+
+	fmt.Fprintf(&b, "# fish completion for %s (generated by %s -completion=fish)\n", s.Name, s.Name)
+
+	// Subcommand Condition
+	if len(s.commands) > 0 {
+		var keys []string
+		for _, spec := range s.specs()[1:] {
+			keys = append(keys, fmt.Sprintf("%q", spec.key()))
+		}
+
+		fmt.Fprintf(&b, "\nfunction %s\n", fn)
+		fmt.Fprintf(&b, "    set -l cmd \"\"\n")
+		fmt.Fprintf(&b, "    for w in (commandline -opc)[2..-1]\n")
+		fmt.Fprintf(&b, "        switch \"$cmd $w\"\n")
+		fmt.Fprintf(&b, "            case %s\n", strings.Join(keys, " "))
+		fmt.Fprintf(&b, "                set cmd \"$cmd $w\"\n")
+		fmt.Fprintf(&b, "        end\n")
+		fmt.Fprintf(&b, "    end\n")
+		fmt.Fprintf(&b, "    test \"$cmd\" = \"$argv[1]\"\n")
+		fmt.Fprintf(&b, "end\n")
+	}
+
+	fmt.Fprintf(&b, "\ncomplete -c %s -f\n", s.Name)
+
+	for _, spec := range s.specs() {
+		prefix := "complete -c " + s.Name
+		if len(s.commands) > 0 {
+			prefix += " -n " + quote(fn+" "+fmt.Sprintf("%q", spec.key()))
+		}
+
+		// Options
+		for _, o := range spec.all() {
+			line := prefix
+			for _, name := range append([]string{o.Name}, o.Aliases...) {
+				line += " -o " + name
+			}
+			switch {
+			case o.Type == TypeFlag || o.Implied != "":
+			case len(o.Choices) > 0:
+				line += " -r -a " + quote(strings.Join(o.Choices, " "))
+			case o.Hint == HintFile:
+				line += " -r -F"
+			case o.Hint == HintDir:
+				line += " -r -a '(__fish_complete_directories)'"
+			default:
+				line += " -r"
+			}
+			fmt.Fprintf(&b, "%s -d %s\n", line, quote(o.Help))
+		}
+
+		// Subcommands
+		for _, c := range spec.commands {
+			fmt.Fprintf(&b, "%s -a %s -d %s\n", prefix, c.Name, quote(c.Summary))
+		}
+
+		// Arguments
+		switch spec.ArgHint {
+		case HintFile:
+			fmt.Fprintf(&b, "%s -F\n", prefix)
+		case HintDir:
+			fmt.Fprintf(&b, "%s -a '(__fish_complete_directories)'\n", prefix)
+		}
+	}
+
+	return b.String()
+}
+
+// Returns the spec and all its subcommands (depth first)
+func (s *Spec) specs() []*Spec {
+	result := []*Spec{s}
+	for _, c := range s.commands {
+		result = append(result, c.specs()...)
+	}
+	return result
+}
+
+// Returns the subcommand names as matched by the scripts, e.g. " remote add"
+// (empty for the top-level spec)
+func (s *Spec) key() string {
+	if s.parent == nil {
+		return ""
+	}
+	return s.parent.key() + " " + s.Name
+}
+
+// Returns true if the option takes its value from the next argument
+func takesSpace(o Opt) bool {
+	return o.Type != TypeFlag && o.Implied == ""
+}
+
+// Returns a case pattern matching the option with one or two dashes,
+// e.g. -s|--s|-skip|--skip
+func pattern(o Opt) string {
+	var result []string
+	for _, name := range append([]string{o.Name}, o.Aliases...) {
+		result = append(result, "-"+name, "--"+name)
+	}
+	return strings.Join(result, "|")
+}
+
+// Returns a case pattern matching the options accepted by the filter
+func patterns(opts []Opt, accept func(o Opt) bool) string {
+	var result []string
+	for _, o := range opts {
+		if accept(o) {
+			result = append(result, pattern(o))
+		}
+	}
+	return strings.Join(result, "|")
+}
+
+// Returns the words completing the option, e.g. -space, or -sep= for an
+// option requiring a value
+func completions(o Opt) []string {
+	var result []string
+	for _, name := range append([]string{o.Name}, o.Aliases...) {
+		if takesSpace(o) {
+			name += "="
+		}
+		result = append(result, "-"+name)
+	}
+	return result
+}
+
+// Returns a shell function name for the program, e.g. _cfg_q for cfg-q
+func function(name string) string {
+	return "_" + unsafePattern.ReplaceAllString(name, "_")
+}
+
+// Returns the text in single quotes (for zsh and fish)
+func quote(text string) string {
+	return "'" + strings.Replace(text, "'", `'\''`, -1) + "'"
+}
+
+// Returns the texts in single quotes, separated by spaces
+func quoteAll(texts []string) string {
+	var result []string
+	for _, text := range texts {
+		result = append(result, quote(text))
+	}
+	return strings.Join(result, " ")
+}
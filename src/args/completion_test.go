@@ -0,0 +1,81 @@
+package args
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestCompletion(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewSpec("tool").AddCompletion()
+	s.EnvPrefix = "ARGSTEST"
+	s.Add(Opt{Name: "v", Aliases: []string{"verbose"}, Help: "Verbose"})
+	s.Add(Opt{Name: "dir", Type: TypeString, Hint: HintDir, Help: "Directory"})
+
+	fetch := s.Command("fetch", nil)
+	fetch.Summary = "Fetch changes"
+	fetch.ArgHint = HintFile
+	fetch.Add(Opt{Name: "format", Type: TypeString, Choices: []string{"csv", "tsv"}, Help: "Format"})
+	s.Command("remote", nil).Command("add", nil)
+
+	// Requested (Even Without A Subcommand)
+	a, err := s.Load([]string{"tool", "-completion=zsh"})
+	assert.Equal(ErrCompletion, err)
+	assert.Equal("zsh", a.GetOpt("completion"))
+
+	_, err = s.Load([]string{"tool", "-completion=tcsh"})
+	assert.EqualError(err, "Args - Invalid value for option -completion: tcsh (must be one of bash, zsh, fish)")
+
+	_, err = s.Completion("tcsh")
+	assert.EqualError(err, "Args - Unknown shell: tcsh (must be one of bash, zsh, fish)")
+
+	// Choices
+	_, err = s.Load([]string{"tool", "fetch", "-format=xml"})
+	assert.EqualError(err, "Args - Invalid value for option -format: xml (must be one of csv, tsv)")
+	assert.Contains(fetch.Help(), "-format=csv|tsv")
+	assert.NotContains(s.Help(), "ARGSTEST_COMPLETION")
+
+	// Bash
+	script, err := s.Completion("bash")
+	assert.Nil(err)
+	for _, line := range []string{
+		`" fetch"|" remote"|" remote add") cmd="$cmd ${COMP_WORDS[i]}" ;;`,
+		`-format|--format) COMPREPLY=($(compgen -W "csv tsv" -- "$cur")); return ;;`,
+		`-dir|--dir) COMPREPLY=($(compgen -d -- "$cur")); return ;;`,
+		`words="-h -help -completion= -v -verbose -dir="`,
+		`cmds="fetch remote"`,
+		`args="file"`,
+		`complete -F _tool tool`,
+	} {
+		assert.Contains(script, line)
+	}
+
+	// Zsh
+	script, err = s.Completion("zsh")
+	assert.Nil(err)
+	assert.True(strings.HasPrefix(script, "#compdef tool\n"))
+	for _, line := range []string{
+		`-format|--format) compadd -- 'csv' 'tsv'; return ;;`,
+		`-dir|--dir) _files -/; return ;;`,
+		`flags=('-h:Help' '-help:Help' '-v:Verbose' '-verbose:Verbose')`,
+		`cmds=('fetch:Fetch changes' 'remote:')`,
+		`compdef _tool tool`,
+	} {
+		assert.Contains(script, line)
+	}
+
+	// Fish
+	script, err = s.Completion("fish")
+	assert.Nil(err)
+	for _, line := range []string{
+		`case " fetch" " remote" " remote add"`,
+		`complete -c tool -n '__tool_command ""' -o v -o verbose -d 'Verbose'`,
+		`complete -c tool -n '__tool_command " fetch"' -o format -r -a 'csv tsv' -d 'Format'`,
+		`complete -c tool -n '__tool_command ""' -a fetch -d 'Fetch changes'`,
+		`complete -c tool -n '__tool_command " fetch"' -F`,
+	} {
+		assert.Contains(script, line)
+	}
+}
@@ -0,0 +1,167 @@
+package args
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Source identifies where an option's value came from. The command line
+// takes precedence over environment variables, which take precedence over
+// defaults:
+//
+// SourceDefault < SourceEnv < SourceArgs
+type Source int
+
+// Sources
+const (
+	SourceNone    Source = 0 // The option is missing (and has no default)
+	SourceDefault Source = 1 // The option's default
+	SourceEnv     Source = 2 // An environment variable (see Opt.Env and Spec.EnvPrefix)
+	SourceArgs    Source = 3 // The command line
+)
+
+// Source-Text
+var sourceText = map[Source]string{
+	SourceNone:    "none",
+	SourceDefault: "default",
+	SourceEnv:     "env",
+	SourceArgs:    "args",
+}
+
+func (s Source) String() string {
+	text, ok := sourceText[s]
+	if ok {
+		return text
+	}
+	return fmt.Sprintf("Source-(%d)", int(s))
+}
+
+// Source returns where the value of the supplied binary option came from
+func (a *Args) Source(key string) Source {
+	key = a.name(key)
+
+	if _, ok := a.env[key]; ok {
+		return SourceEnv
+	}
+
+	if a.HasOpt(key) {
+		return SourceArgs
+	}
+
+	if a.spec != nil {
+		if o := a.spec.find(key); o != nil && o.Default != "" {
+			return SourceDefault
+		}
+	}
+
+	return SourceNone
+}
+
+// Origin describes where the value of the supplied binary option came
+// from, e.g. "args:-s", "env:COLS_SKIP" or "default" (empty if missing)
+func (a *Args) Origin(key string) string {
+	key = a.name(key)
+
+	switch a.Source(key) {
+	case SourceArgs:
+		return "args:-" + key
+	case SourceEnv:
+		return "env:" + a.env[key]
+	case SourceDefault:
+		return "default"
+	}
+
+	return ""
+}
+
+// Sources returns a line for each binary option of the spec that has a
+// value, showing the value and its origin (see Origin):
+//
+// -s=2    env:COLS_SKIP
+// -sep=;  args:-sep
+func (a *Args) Sources() string {
+	if a.spec == nil {
+		return ""
+	}
+
+	var lines [][2]string
+	pad := 0
+
+	for _, o := range a.spec.all() {
+		if o.Type == TypeFlag || a.Source(o.Name) == SourceNone {
+			continue
+		}
+
+		opt := "-" + o.Name + "=" + a.GetOpt(o.Name)
+		lines = append(lines, [2]string{opt, a.Origin(o.Name)})
+		if len(opt) > pad {
+			pad = len(opt)
+		}
+	}
+
+	var result string
+	for _, line := range lines {
+		result += fmt.Sprintf("%-*s  %s\n", pad, line[0], line[1])
+	}
+	return result
+}
+
+// Takes the values of missing binary options from their environment
+// variables (if set)
+func (a *Args) applyEnv() error {
+	for _, o := range a.spec.all() {
+		name := a.spec.envName(o)
+		if o.Type == TypeFlag || name == "" || a.HasOpt(o.Name) {
+			continue
+		}
+
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		if err := o.check(val); err != nil {
+			return fmt.Errorf("%s (from %s)", err.Error(), name)
+		}
+
+		if a.env == nil {
+			a.env = make(map[string]string)
+		}
+		a.env[o.Name] = name
+		a.binOpts = append(a.binOpts, keyVal{o.Name, val})
+	}
+
+	return nil
+}
+
+// Returns the environment variable of the option: its Env, or one derived
+// from the EnvPrefix of the spec (or a spec it inherits from) and the
+// option's first name longer than a letter, e.g. COLS_SKIP for -s, -skip
+func (s *Spec) envName(o Opt) string {
+	if o.noEnv {
+		return ""
+	}
+
+	if o.Env != "" {
+		return o.Env
+	}
+
+	for spec := s; spec != nil; spec = spec.parent {
+		if spec.EnvPrefix == "" {
+			continue
+		}
+
+		name := o.Name
+		for _, alias := range o.Aliases {
+			if len(name) == 1 && len(alias) > 1 {
+				name = alias
+			}
+		}
+
+		name = strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToUpper(name))
+		return spec.EnvPrefix + "_" + name
+	}
+
+	return ""
+}
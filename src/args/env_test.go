@@ -0,0 +1,79 @@
+package args
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestEnv(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewSpec("cols")
+	s.EnvPrefix = "ARGSTEST"
+	s.Add(Opt{Name: "space", Help: "Split using whitespace"})
+	s.Add(Opt{Name: "s", Aliases: []string{"skip"}, Type: TypeInt, Default: "0", Help: "Skip"})
+	s.Add(Opt{Name: "sep", Type: TypeString, Default: ",", Help: "Separator"})
+	s.Add(Opt{Name: "db", Type: TypeString, Env: "ARGSTEST_DSN", Required: true, Help: "Database"})
+	s.Add(Opt{Name: "n", Type: TypeInt, Help: "Count"})
+
+	assert.Nil(os.Setenv("ARGSTEST_SKIP", "2"))
+	assert.Nil(os.Setenv("ARGSTEST_SEP", ";"))
+	assert.Nil(os.Setenv("ARGSTEST_DSN", "prod"))
+	assert.Nil(os.Setenv("ARGSTEST_SPACE", "true"))
+	defer os.Unsetenv("ARGSTEST_SKIP")
+	defer os.Unsetenv("ARGSTEST_SEP")
+	defer os.Unsetenv("ARGSTEST_DSN")
+	defer os.Unsetenv("ARGSTEST_SPACE")
+
+	// Command Line > Env > Default
+	a, err := s.Load([]string{"cols", "-sep=|"})
+	assert.Nil(err)
+	assert.Equal(2, a.GetOptI("skip"))
+	assert.Equal("|", a.GetOpt("sep"))
+	assert.Equal("prod", a.GetOpt("db"))
+	assert.False(a.IsOn("space")) // Flags Aren't Read From The Environment
+
+	assert.Equal(SourceEnv, a.Source("skip"))
+	assert.Equal(SourceArgs, a.Source("sep"))
+	assert.Equal(SourceNone, a.Source("n"))
+	assert.Equal("env:ARGSTEST_SKIP", a.Origin("s"))
+	assert.Equal("args:-sep", a.Origin("sep"))
+	assert.Equal("", a.Origin("n"))
+	assert.Equal("env", SourceEnv.String())
+
+	assert.Nil(os.Unsetenv("ARGSTEST_SEP"))
+	a, err = s.Load([]string{"cols"})
+	assert.Nil(err)
+	assert.Equal(",", a.GetOpt("sep"))
+	assert.Equal(SourceDefault, a.Source("sep"))
+
+	assert.Equal(`-s=2      env:ARGSTEST_SKIP
+-sep=,    default
+-db=prod  env:ARGSTEST_DSN
+`, a.Sources())
+
+	// Required Options Can Come From The Environment
+	assert.Nil(os.Unsetenv("ARGSTEST_DSN"))
+	_, err = s.Load([]string{"cols"})
+	assert.EqualError(err, "Args - Missing required option: -db")
+
+	// Invalid Values Name The Variable
+	assert.Nil(os.Setenv("ARGSTEST_SKIP", "x"))
+	_, err = s.Load([]string{"cols", "-db=prod"})
+	assert.EqualError(err, "Args - Invalid value for option -s: x (must be an int) (from ARGSTEST_SKIP)")
+
+	// Help Shows The Variables
+	assert.Equal(`
+cols
+----
+
+-h, -help      Help
+-space         Split using whitespace
+-s, -skip=INT  Skip (default: 0) (env: ARGSTEST_SKIP)
+-sep=STRING    Separator (default: ,) (env: ARGSTEST_SEP)
+-db=STRING     Database (required) (env: ARGSTEST_DSN)
+-n=INT         Count (env: ARGSTEST_N)
+
+`, s.Help())
+}
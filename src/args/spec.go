@@ -0,0 +1,653 @@
+package args
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type is the type of an option's value
+type Type string
+
+// Types
+const (
+	TypeFlag     Type = "flag" // Unary option: -debug
+	TypeString   Type = "string"
+	TypeInt      Type = "int"
+	TypeFloat    Type = "float"
+	TypeBool     Type = "bool" // Binary option: -debug=yes (see LookupOptB)
+	TypeDuration Type = "duration"
+)
+
+// Negative Numbers (Non-Option Arguments If Spec.NumericArgs Is Set)
+var numberPattern = regexp.MustCompile(`^-(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$`)
+
+// ErrHelp is returned by Spec.Load if -h (or -help) is supplied
+var ErrHelp = errors.New("Args - Help requested")
+
+// ErrCompletion is returned by Spec.Load if -completion=SHELL is supplied
+// (see Spec.AddCompletion)
+var ErrCompletion = errors.New("Args - Completion script requested")
+
+// Opt describes an expected option. All options except flags are binary
+// (-name=value).
+type Opt struct {
+	Name        string   // e.g. "threads" for -threads=4
+	Aliases     []string // Alternative names, e.g. "t" for -t=4
+	Type        Type     // Defaults to TypeFlag
+	Default     string   // Returned by GetOpt when the option is missing (not for flags)
+	Implied     string   // Value used when the option is given without one (e.g. -s means -s=1)
+	Placeholder string   // Shown in the help text, e.g. N for -skip=N (defaults to the type)
+	Help        string   // Description shown in the help text
+	Required    bool     // The option must be supplied (not for flags)
+	Env         string   // Environment variable used if the option is missing (see Spec.EnvPrefix)
+	Choices     []string // Allowed values (also offered by shell completion)
+	Hint        Hint     // What the value is, for shell completion (e.g. HintFile)
+	noEnv       bool     // Never read from the environment (see Spec.EnvPrefix)
+}
+
+// Handler runs a subcommand (see Spec.Command)
+type Handler func(a *Args)
+
+// Spec describes the options a program expects. Parsing with a spec
+// rejects unknown options and values of the wrong type, and -h prints
+// help text generated from the spec:
+//
+//	spec := args.NewSpec("cols")
+//	spec.Add(args.Opt{Name: "space", Help: "Split using whitespace"})
+//	spec.Add(args.Opt{Name: "s", Type: args.TypeInt, Implied: "1", Help: "Skip first N lines"})
+//	args := spec.Parse()
+//
+// A spec can also have subcommands, each with its own spec (see Command).
+type Spec struct {
+	Name        string   // Program (or subcommand) name, the title of the help text
+	Usage       string   // Synopsis shown above the options, e.g. "cols [options] COLUMN..."
+	About       string   // Text shown below the options (description, examples)
+	Summary     string   // One-line description shown in the parent's list of subcommands
+	NumericArgs bool     // Arguments such as -5 or -2.5 are not options (inherited by subcommands)
+	EnvPrefix   string   // Missing binary options are read from PREFIX_NAME, e.g. COLS_SKIP (inherited by subcommands)
+	ArgHint     Hint     // What the non-option arguments are, for shell completion (e.g. HintFile)
+	Passthrough []string // Prefixes of undeclared options that are accepted as is, e.g. "cfg." (inherited by subcommands)
+	UsageExit   int      // Exit code for usage errors, defaults to 1 (inherited by subcommands, see Fail)
+	opts        []Opt
+	parent      *Spec     // Nil for the top-level spec
+	commands    []*Spec   // Subcommands (in order added)
+	handler     Handler   // Runs this subcommand
+	bindings    []binding // Structs filled after parsing (see Bind)
+	completion  bool      // -completion=SHELL prints a completion script (see AddCompletion)
+}
+
+// NewSpec returns a spec containing only the help option (-h, -help)
+func NewSpec(name string) *Spec {
+	s := &Spec{Name: name}
+	s.Add(Opt{Name: "h", Aliases: []string{"help"}, Help: "Help"})
+	return s
+}
+
+// Add adds an option to the spec. It exits(1) if the option's name is
+// already taken, its default doesn't match its type or it is a flag that
+// is required or has a default (a flag is only on when it is given).
+func (s *Spec) Add(o Opt) *Spec {
+	if o.Type == "" {
+		o.Type = TypeFlag
+	}
+
+	if o.Type == TypeFlag && (o.Required || o.Default != "") {
+		fmt.Fprintf(os.Stderr, "Args - Bad option spec - A flag can't be required or have a default: -%s\n", o.Name)
+		os.Exit(1)
+	}
+
+	for _, name := range append([]string{o.Name}, o.Aliases...) {
+		if name == "" || s.own(name) != nil {
+			fmt.Fprintf(os.Stderr, "Args - Bad option spec - Name is empty or taken: '%s'\n", name)
+			os.Exit(1)
+		}
+	}
+
+	for _, val := range []string{o.Default, o.Implied} {
+		if val != "" && o.check(val) != nil {
+			fmt.Fprintf(os.Stderr, "Args - Bad option spec - %s\n", o.check(val).Error())
+			os.Exit(1)
+		}
+	}
+
+	s.opts = append(s.opts, o)
+	return s
+}
+
+// Command adds a subcommand and returns its spec (to which its options
+// are added). The options of the parent are inherited by the subcommand,
+// so they may be given before or after the subcommand's name. After
+// parsing, New (and Parse) invoke the handler of the chosen subcommand:
+//
+//	fetch := spec.Command("fetch", runFetch)
+//	fetch.Add(args.Opt{Name: "force", Help: "Overwrite local changes"})
+//	spec.Parse() // tool -v fetch -force => runFetch(args)
+//
+// It exits(1) if a subcommand with the same name has already been added.
+func (s *Spec) Command(name string, handler Handler) *Spec {
+	if name == "" || s.command(name) != nil {
+		fmt.Fprintf(os.Stderr, "Args - Bad option spec - Command name is empty or taken: '%s'\n", name)
+		os.Exit(1)
+	}
+
+	c := NewSpec(name)
+	c.parent = s
+	c.handler = handler
+	s.commands = append(s.commands, c)
+	return c
+}
+
+// Parse parses os.Args (see New)
+func (s *Spec) Parse() *Args {
+	return s.New(os.Args)
+}
+
+// New parses the supplied arguments. If help is requested it prints the
+// help text (of the chosen subcommand) and exits(0), and if a completion
+// script is requested it prints the script and exits(0). If the arguments
+// don't match the spec it prints the error and exits with UsageExit (see
+// Fail). If a subcommand was chosen, its handler is invoked before
+// returning.
+func (s *Spec) New(raws []string) *Args {
+	a, err := s.Load(raws)
+
+	if err == ErrHelp {
+		fmt.Fprint(os.Stderr, a.spec.Help())
+		os.Exit(0)
+	}
+
+	if err == ErrCompletion {
+		script, err := s.root().Completion(a.GetOpt("completion"))
+		exitOn(err)
+		fmt.Print(script)
+		os.Exit(0)
+	}
+
+	if err != nil {
+		s.Fail(err.Error())
+	}
+
+	if a.spec.handler != nil {
+		a.spec.handler(a)
+	}
+
+	return a
+}
+
+// Fail prints the usage error, followed by a pointer to -h, and exits with
+// UsageExit (1 if unset). Use it for errors the spec can't check, such as
+// a missing argument:
+//
+//	if args.Size() < 2 {
+//		spec.Fail("Missing FILE")
+//	}
+func (s *Spec) Fail(msg string) {
+	fmt.Fprintf(os.Stderr, "%s\nUse -h for help\n", msg)
+	os.Exit(s.usageExit())
+}
+
+// Load parses the supplied arguments (the first is the program). It
+// returns ErrHelp if help is requested (or ErrCompletion if a completion
+// script is), else an error for an unknown
+// option or subcommand, a missing or badly typed value, or a missing
+// required option. If the spec has subcommands, the first non-option
+// argument chooses one (see Args.Command) and is not included in the
+// arguments. Load doesn't invoke the subcommand's handler.
+//
+// Options may be given with one or two dashes. A value follows an equals
+// sign or is the next argument (unless the option has an implied value):
+//
+//	-threads=4  --threads=4  -threads 4  --threads 4
+//
+// One-letter options can be clustered: -xvf is -x -v -f (only the last
+// may take a value). The argument -- ends the options, so everything after
+// it is a non-option argument (as is a lone dash). If NumericArgs is set,
+// arguments such as -5 or -2.5 are non-option arguments too. Options that
+// aren't in the spec but start with one of the Passthrough prefixes are
+// kept as given (a value requires an equals sign).
+//
+// A binary option missing from the command line is read from its
+// environment variable (see Opt.Env and EnvPrefix), if set, before
+// falling back to its default. Use Args.Source to tell which it was.
+func (s *Spec) Load(raws []string) (*Args, error) {
+	a := &Args{raws: make([]string, len(raws)), spec: s}
+	copy(a.raws, raws)
+
+	var firstErr error
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	terminated := false
+	for i := 0; i < len(raws); i++ {
+		raw := raws[i]
+
+		// End Of Options: --
+		if raw == "--" && i > 0 && !terminated {
+			terminated = true
+			continue
+		}
+
+		// Non-Option (The Program, A Lone Dash, Anything After --, Or A Number If Allowed)
+		if i == 0 || terminated || !strings.HasPrefix(raw, "-") || raw == "-" || (a.spec.numeric() && numberPattern.MatchString(raw)) {
+
+			// Subcommand
+			if i > 0 && len(a.spec.commands) > 0 {
+				c := a.spec.command(raw)
+				if c == nil {
+					fail(errors.New("Args - Unknown command: " + raw + " (must be one of " + strings.Join(a.spec.commandNames(), ", ") + ")"))
+					continue
+				}
+				a.spec = c
+				continue
+			}
+
+			a.vals = append(a.vals, raw)
+			continue
+		}
+
+		// Option: -name, -name=value, -name value (Or With Two Dashes)
+		dashes := "-"
+		if strings.HasPrefix(raw, "--") {
+			dashes = "--"
+		}
+		tokens := strings.SplitN(strings.TrimPrefix(raw, dashes), "=", 2)
+		o := a.spec.find(tokens[0])
+
+		// Cluster Of One-Letter Options: -abc (Only The Last May Take A Value)
+		if o == nil && dashes == "-" {
+			if cluster := a.spec.cluster(tokens[0]); cluster != nil {
+				for _, flag := range cluster[:len(cluster)-1] {
+					a.uniOpts = append(a.uniOpts, flag.Name)
+				}
+				o = cluster[len(cluster)-1]
+			}
+		}
+
+		// Undeclared Option With A Passthrough Prefix: -cfg.threads=4
+		if o == nil && a.spec.passes(tokens[0]) {
+			if len(tokens) == 2 {
+				a.binOpts = append(a.binOpts, keyVal{tokens[0], tokens[1]})
+			} else {
+				a.uniOpts = append(a.uniOpts, tokens[0])
+			}
+			continue
+		}
+
+		if o == nil {
+			fail(errors.New("Args - Unknown option: " + raw))
+			continue
+		}
+
+		// Flag
+		if o.Type == TypeFlag {
+			if len(tokens) == 2 {
+				fail(errors.New("Args - Option takes no value: " + raw))
+				continue
+			}
+			a.uniOpts = append(a.uniOpts, o.Name)
+			continue
+		}
+
+		// Value: After =, Implied, Or The Next Argument
+		var val string
+		switch {
+		case len(tokens) == 2:
+			val = tokens[1]
+		case o.Implied != "":
+			val = o.Implied
+		case i+1 < len(raws):
+			i++
+			val = raws[i]
+		default:
+			fail(errors.New("Args - Option requires a value: " + raw + "=" + o.placeholder()))
+			continue
+		}
+
+		if err := o.check(val); err != nil {
+			fail(err)
+			continue
+		}
+
+		a.binOpts = append(a.binOpts, keyVal{o.Name, val})
+	}
+
+	// Help Takes Precedence
+	if a.IsOn("h") {
+		return a, ErrHelp
+	}
+
+	// So Does A Completion Script
+	if s.root().completion && a.HasOpt("completion") {
+		return a, ErrCompletion
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// Subcommand Must Be Chosen
+	if len(a.spec.commands) > 0 {
+		return nil, errors.New("Args - Missing command (must be one of " + strings.Join(a.spec.commandNames(), ", ") + ")")
+	}
+
+	// Environment Variables (For Missing Options)
+	if err := a.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	// Required Options (Including Inherited Ones)
+	for _, o := range a.spec.all() {
+		if o.Required && !a.HasOpt(o.Name) {
+			return nil, errors.New("Args - Missing required option: -" + o.Name)
+		}
+	}
+
+	// Bound Structs
+	if err := a.fill(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Help returns the help text: the name, the usage, a table of the options,
+// the subcommands and the about text. The help text of a subcommand also
+// lists the options it inherits.
+func (s *Spec) Help() string {
+	title := s.path()
+	result := "\n" + title + "\n" + strings.Repeat("-", len(title)) + "\n\n"
+
+	if s.Usage != "" {
+		result += s.Usage + "\n\n"
+	}
+
+	// Own And Inherited Options
+	own := s.opts
+	inherited := s.all()[len(own):]
+
+	// Align Descriptions
+	pad := 0
+	for _, o := range s.all() {
+		if len(o.synopsis()) > pad {
+			pad = len(o.synopsis())
+		}
+	}
+	for _, c := range s.commands {
+		if len(c.Name) > pad {
+			pad = len(c.Name)
+		}
+	}
+
+	result += s.table(own, pad)
+
+	if len(inherited) > 0 {
+		result += "\nGlobal options:\n\n" + s.table(inherited, pad)
+	}
+
+	if len(s.commands) > 0 {
+		result += "\nCommands:\n\n"
+		for _, c := range s.commands {
+			result += strings.TrimRight(fmt.Sprintf("%-*s  %s", pad, c.Name, c.Summary), " ") + "\n"
+		}
+		result += "\nUse '" + title + " COMMAND -h' for help on a command.\n"
+	}
+
+	if s.About != "" {
+		result += "\n\n" + strings.Trim(s.About, "\n") + "\n"
+	}
+
+	return result + "\n"
+}
+
+// Returns the options as lines of the help text
+func (s *Spec) table(opts []Opt, pad int) string {
+	var result string
+
+	for _, o := range opts {
+		text := o.Help
+		if o.Default != "" {
+			text += " (default: " + o.Default + ")"
+		}
+		if o.Required {
+			text += " (required)"
+		}
+		if env := s.envName(o); env != "" && o.Type != TypeFlag {
+			text += " (env: " + env + ")"
+		}
+		result += strings.TrimRight(fmt.Sprintf("%-*s  %s", pad, o.synopsis(), text), " ") + "\n"
+	}
+
+	return result
+}
+
+// Returns the top-level spec
+func (s *Spec) root() *Spec {
+	for s.parent != nil {
+		s = s.parent
+	}
+	return s
+}
+
+// Returns true if the option name starts with a Passthrough prefix of the
+// spec (or a spec it inherits from)
+func (s *Spec) passes(name string) bool {
+	for spec := s; spec != nil; spec = spec.parent {
+		for _, prefix := range spec.Passthrough {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns the program and subcommand names, e.g. "tool fetch"
+func (s *Spec) path() string {
+	if s.parent == nil {
+		return s.Name
+	}
+	return s.parent.path() + " " + s.Name
+}
+
+// Returns the spec's own options followed by those it inherits (skipping
+// any hidden by an option with the same name or alias)
+func (s *Spec) all() []Opt {
+	result := append([]Opt(nil), s.opts...)
+
+	for p := s.parent; p != nil; p = p.parent {
+		for _, o := range p.opts {
+			if taken(result, o) {
+				continue
+			}
+			result = append(result, o)
+		}
+	}
+
+	return result
+}
+
+// Returns true if any name or alias of the option is used in the list
+func taken(opts []Opt, o Opt) bool {
+	for _, name := range append([]string{o.Name}, o.Aliases...) {
+		for _, other := range opts {
+			if other.Name == name {
+				return true
+			}
+			for _, alias := range other.Aliases {
+				if alias == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Returns the exit code for usage errors (see UsageExit)
+func (s *Spec) usageExit() int {
+	for spec := s; spec != nil; spec = spec.parent {
+		if spec.UsageExit != 0 {
+			return spec.UsageExit
+		}
+	}
+	return 1
+}
+
+// Returns true if the spec (or a spec it inherits from) has NumericArgs set
+func (s *Spec) numeric() bool {
+	for spec := s; spec != nil; spec = spec.parent {
+		if spec.NumericArgs {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the options of a cluster of one-letter options, e.g. -xvf (nil
+// if any letter is unknown or any but the last takes a value)
+func (s *Spec) cluster(letters string) []*Opt {
+	if len(letters) < 2 {
+		return nil
+	}
+
+	var result []*Opt
+	for i, letter := range letters {
+		o := s.find(string(letter))
+		if o == nil || (o.Type != TypeFlag && i < len(letters)-1) {
+			return nil
+		}
+		result = append(result, o)
+	}
+
+	return result
+}
+
+// Returns the subcommand with the supplied name (nil if none)
+func (s *Spec) command(name string) *Spec {
+	for _, c := range s.commands {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Returns the names of the subcommands
+func (s *Spec) commandNames() []string {
+	var result []string
+	for _, c := range s.commands {
+		result = append(result, c.Name)
+	}
+	return result
+}
+
+// Returns the option with the supplied name or alias, looking in the
+// spec and then in the specs it inherits from (nil if none)
+func (s *Spec) find(name string) *Opt {
+	for spec := s; spec != nil; spec = spec.parent {
+		if o := spec.own(name); o != nil {
+			return o
+		}
+	}
+	return nil
+}
+
+// Returns the spec's own option with the supplied name or alias (nil if none)
+func (s *Spec) own(name string) *Opt {
+	for i := range s.opts {
+		o := &s.opts[i]
+		if o.Name == name {
+			return o
+		}
+		for _, alias := range o.Aliases {
+			if alias == name {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the option as shown in the help text, e.g. -s, -skip[=N]
+func (o *Opt) synopsis() string {
+	var names []string
+	for _, name := range append([]string{o.Name}, o.Aliases...) {
+		names = append(names, "-"+name)
+	}
+	result := strings.Join(names, ", ")
+
+	switch {
+	case o.Type == TypeFlag:
+	case o.Implied != "":
+		result += "[=" + o.placeholder() + "]"
+	default:
+		result += "=" + o.placeholder()
+	}
+
+	return result
+}
+
+// Returns the placeholder for the option's value (the choices, if any,
+// e.g. bash|zsh|fish)
+func (o *Opt) placeholder() string {
+	if o.Placeholder != "" {
+		return o.Placeholder
+	}
+	if len(o.Choices) > 0 {
+		return strings.Join(o.Choices, "|")
+	}
+	return strings.ToUpper(string(o.Type))
+}
+
+// Checks that the value matches the option's type
+func (o *Opt) check(val string) error {
+	var err error
+
+	switch o.Type {
+	case TypeInt:
+		_, err = strconv.Atoi(val)
+	case TypeFloat:
+		_, err = strconv.ParseFloat(val, 64)
+	case TypeDuration:
+		_, err = time.ParseDuration(val)
+	case TypeBool:
+		_, err = ParseB(val)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Args - Invalid value for option -%s: %s (must be %s)", o.Name, val, article(o.Type))
+	}
+
+	// Choices
+	if len(o.Choices) > 0 && !contains(o.Choices, val) {
+		return fmt.Errorf("Args - Invalid value for option -%s: %s (must be one of %s)", o.Name, val, strings.Join(o.Choices, ", "))
+	}
+
+	return nil
+}
+
+// Returns true if the list contains the string
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the type with its indefinite article, e.g. "an int"
+func article(t Type) string {
+	if strings.ContainsAny(string(t)[:1], "aeiou") {
+		return "an " + string(t)
+	}
+	return "a " + string(t)
+}
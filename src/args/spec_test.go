@@ -0,0 +1,257 @@
+package args
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// Returns a spec like that of the cols command
+func testSpec() *Spec {
+	s := NewSpec("cols")
+	s.Usage = "cols [options] COLUMN..."
+	s.About = "Prints the columns."
+	s.Add(Opt{Name: "space", Help: "Split using whitespace"})
+	s.Add(Opt{Name: "s", Aliases: []string{"skip"}, Type: TypeInt, Implied: "1", Default: "0", Placeholder: "N", Help: "Skip first N lines"})
+	s.Add(Opt{Name: "sep", Type: TypeString, Help: "Separator"})
+	s.Add(Opt{Name: "wait", Type: TypeDuration, Required: true, Help: "Wait"})
+	return s
+}
+
+func TestSpec(t *testing.T) {
+	assert := assert.New(t)
+	s := testSpec()
+
+	// Options (Aliases Map To Names)
+	a, err := s.Load([]string{"cols", "2", "-space", "--skip=3", "-wait=1s", "13"})
+	assert.Nil(err)
+	assert.Equal(3, a.Size())
+	assert.Equal("13", a.Get(2))
+	assert.True(a.IsOn("space"))
+	assert.True(a.HasOpt("s"))
+	assert.True(a.HasOpt("skip"))
+	assert.Equal(3, a.GetOptI("s"))
+	assert.Equal([]string{"s", "wait"}, a.OptKeys())
+
+	// Implied Values And Defaults
+	a, err = s.Load([]string{"cols", "-s", "-wait=1s"})
+	assert.Nil(err)
+	assert.Equal("1", a.GetOpt("skip"))
+
+	a, err = s.Load([]string{"cols", "-wait=1s"})
+	assert.Nil(err)
+	assert.False(a.HasOpt("s"))
+	assert.Equal("0", a.GetOpt("s"))
+
+	// Errors
+	for raw, text := range map[string]string{
+		"-spce":     "Unknown option: -spce",
+		"-space=1":  "Option takes no value: -space=1",
+		"-sep":      "Option requires a value: -sep=STRING",
+		"-skip=x":   "Invalid value for option -s: x (must be an int)",
+		"-wait=3":   "Invalid value for option -wait: 3 (must be a duration)",
+		"-sep=,":    "Missing required option: -wait",
+		"-space -h": "Help requested",
+	} {
+		_, err := s.Load(append([]string{"cols"}, strings.Fields(raw)...))
+		assert.NotNil(err, raw)
+		assert.Contains(err.Error(), text, raw)
+	}
+
+	// Help Takes Precedence
+	_, err = s.Load([]string{"cols", "-spce", "--help"})
+	assert.Equal(ErrHelp, err)
+
+	// Help Text
+	assert.Equal(`
+cols
+----
+
+cols [options] COLUMN...
+
+-h, -help       Help
+-space          Split using whitespace
+-s, -skip[=N]   Skip first N lines (default: 0)
+-sep=STRING     Separator
+-wait=DURATION  Wait (required)
+
+
+Prints the columns.
+
+`, s.Help())
+}
+
+func TestCommands(t *testing.T) {
+	assert := assert.New(t)
+
+	var ran []string
+	run := func(a *Args) { ran = append(ran, a.Command()) }
+
+	s := NewSpec("tool")
+	s.Add(Opt{Name: "v", Aliases: []string{"verbose"}, Help: "Verbose"})
+	s.Add(Opt{Name: "dir", Type: TypeString, Default: ".", Help: "Directory"})
+
+	fetch := s.Command("fetch", run)
+	fetch.Summary = "Fetch changes"
+	fetch.Usage = "tool fetch [options] REMOTE"
+	fetch.Add(Opt{Name: "force", Help: "Overwrite local changes"})
+
+	remote := s.Command("remote", nil)
+	remote.Summary = "Manage remotes"
+	remote.Command("add", run).Add(Opt{Name: "url", Type: TypeString, Required: true, Help: "URL"})
+
+	// Global Options Before Or After The Command
+	a, err := s.Load([]string{"tool", "-v", "fetch", "origin", "-force", "-dir=/tmp"})
+	assert.Nil(err)
+	assert.Equal("fetch", a.Command())
+	assert.Equal(2, a.Size())
+	assert.Equal("origin", a.Get(1))
+	assert.True(a.IsOn("verbose"))
+	assert.True(a.IsOn("force"))
+	assert.Equal("/tmp", a.GetOpt("dir"))
+
+	// Nested Commands (Inherited Defaults)
+	a, err = s.Load([]string{"tool", "remote", "add", "-url=x"})
+	assert.Nil(err)
+	assert.Equal("remote add", a.Command())
+	assert.Equal(".", a.GetOpt("dir"))
+
+	// Usage Exit Code (Inherited)
+	assert.Equal(1, fetch.usageExit())
+	s.UsageExit = 2
+	assert.Equal(2, fetch.usageExit())
+	fetch.UsageExit = 3
+	assert.Equal(3, fetch.usageExit())
+
+	// Dispatch
+	s.New([]string{"tool", "fetch"})
+	s.New([]string{"tool", "remote", "add", "-url=x"})
+	assert.Equal([]string{"fetch", "remote add"}, ran)
+
+	// Errors
+	for raw, text := range map[string]string{
+		"":                "Missing command (must be one of fetch, remote)",
+		"push":            "Unknown command: push (must be one of fetch, remote)",
+		"-force fetch":    "Unknown option: -force",
+		"remote":          "Missing command (must be one of add)",
+		"remote add":      "Missing required option: -url",
+		"fetch -force=no": "Option takes no value",
+	} {
+		_, err := s.Load(append([]string{"tool"}, strings.Fields(raw)...))
+		assert.NotNil(err, raw)
+		assert.Contains(err.Error(), text, raw)
+	}
+
+	// Help For The Chosen Command
+	a, err = s.Load([]string{"tool", "fetch", "-h"})
+	assert.Equal(ErrHelp, err)
+	assert.Equal(fetch.Help(), a.spec.Help())
+
+	assert.Equal(`
+tool fetch
+----------
+
+tool fetch [options] REMOTE
+
+-h, -help     Help
+-force        Overwrite local changes
+
+Global options:
+
+-v, -verbose  Verbose
+-dir=STRING   Directory (default: .)
+
+`, fetch.Help())
+
+	assert.Equal(`
+tool
+----
+
+-h, -help     Help
+-v, -verbose  Verbose
+-dir=STRING   Directory (default: .)
+
+Commands:
+
+fetch         Fetch changes
+remote        Manage remotes
+
+Use 'tool COMMAND -h' for help on a command.
+
+`, s.Help())
+}
+
+func TestSpecSyntax(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewSpec("tar")
+	s.Add(Opt{Name: "x", Help: "Extract"})
+	s.Add(Opt{Name: "v", Help: "Verbose"})
+	s.Add(Opt{Name: "f", Aliases: []string{"file"}, Type: TypeString, Help: "Archive"})
+	s.Add(Opt{Name: "n", Type: TypeInt, Implied: "1", Help: "Count"})
+	s.Add(Opt{Name: "xv", Help: "Not a cluster"})
+
+	load := func(raws ...string) *Args {
+		a, err := s.Load(append([]string{"tar"}, raws...))
+		assert.Nil(err, strings.Join(raws, " "))
+		return a
+	}
+
+	// Space-Separated Values (One Or Two Dashes)
+	a := load("-f", "a.tar", "b")
+	assert.Equal("a.tar", a.GetOpt("f"))
+	assert.Equal([]string{"tar", "b"}, a.vals)
+	assert.Equal("a.tar", load("--file", "a.tar").GetOpt("f"))
+	assert.Equal("a.tar", load("--file=a.tar").GetOpt("f"))
+	assert.Equal("-a.tar", load("-f", "-a.tar").GetOpt("f"))
+
+	// Implied Values Don't Take The Next Argument
+	a = load("-n", "5")
+	assert.Equal(1, a.GetOptI("n"))
+	assert.Equal([]string{"tar", "5"}, a.vals)
+
+	// Clusters (The Last Option May Take A Value)
+	a = load("-vxf", "a.tar")
+	assert.True(a.IsOn("v"))
+	assert.True(a.IsOn("x"))
+	assert.Equal("a.tar", a.GetOpt("f"))
+	assert.Equal("b.tar", load("-xf=b.tar").GetOpt("f"))
+
+	a = load("-xv") // A Known Name Beats A Cluster
+	assert.True(a.IsOn("xv"))
+	assert.False(a.IsOn("x"))
+
+	// Terminator
+	a = load("-x", "--", "-v", "--", "-5")
+	assert.True(a.IsOn("x"))
+	assert.False(a.IsOn("v"))
+	assert.Equal([]string{"tar", "-v", "--", "-5"}, a.vals)
+
+	// Negative Numbers
+	_, err := s.Load([]string{"tar", "-5"})
+	assert.NotNil(err)
+	s.NumericArgs = true
+	a = load("-5", "-2.5", "-1e3", "-n", "-3")
+	assert.Equal([]string{"tar", "-5", "-2.5", "-1e3", "-3"}, a.vals)
+
+	// Passthrough Prefixes
+	_, err = s.Load([]string{"tar", "-cfg.threads=4"})
+	assert.NotNil(err)
+	s.Passthrough = []string{"cfg."}
+	a = load("-cfg.threads=4", "--cfg.verbose", "-x")
+	assert.Equal("4", a.GetOpt("cfg.threads"))
+	assert.True(a.IsOn("cfg.verbose"))
+	assert.True(a.IsOn("x"))
+
+	// Errors
+	for raw, text := range map[string]string{
+		"-f":   "Option requires a value: -f=STRING",
+		"-fx":  "Unknown option: -fx",
+		"-xy":  "Unknown option: -xy",
+		"--xf": "Unknown option: --xf",
+	} {
+		_, err := s.Load(append([]string{"tar"}, strings.Fields(raw)...))
+		assert.NotNil(err, raw)
+		assert.Contains(err.Error(), text, raw)
+	}
+}
@@ -0,0 +1,148 @@
+package args
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LookupOptI returns the value for the supplied key as an integer, or an
+// error if the option is not found or is not an integer
+func (a *Args) LookupOptI(key string) (int, error) {
+	s, err := a.LookupOpt(key)
+	if err != nil {
+		return 0, err
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, invalid("integer", key, s)
+	}
+	return i, nil
+}
+
+// LookupOptF64 returns the value for the supplied key as a float64, or an
+// error if the option is not found or is not a number
+func (a *Args) LookupOptF64(key string) (float64, error) {
+	s, err := a.LookupOpt(key)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, invalid("number", key, s)
+	}
+	return f, nil
+}
+
+// LookupOptB returns the value for the supplied key as a boolean (see
+// ParseB), or an error if the option is not found or is not a boolean.
+func (a *Args) LookupOptB(key string) (bool, error) {
+	s, err := a.LookupOpt(key)
+	if err != nil {
+		return false, err
+	}
+
+	b, err := ParseB(s)
+	if err != nil {
+		return false, invalid("boolean", key, s)
+	}
+	return b, nil
+}
+
+// LookupOptDuration returns the value for the supplied key as a
+// time.Duration (e.g. "1m30s"), or an error if the option is not found
+// or is not a duration
+func (a *Args) LookupOptDuration(key string) (time.Duration, error) {
+	s, err := a.LookupOpt(key)
+	if err != nil {
+		return 0, err
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, invalid("duration", key, s)
+	}
+	return d, nil
+}
+
+// LookupOptList returns the values of all occurrences of the supplied key
+// (in order), e.g. ["a", "b"] for -k=a -k=b. If the option is not found
+// it returns its default (see GetOpt) or an error if it has none.
+func (a *Args) LookupOptList(key string) ([]string, error) {
+	var result []string
+
+	name := a.name(key)
+	for _, b := range a.binOpts {
+		if b.key == name {
+			result = append(result, b.val)
+		}
+	}
+
+	if result == nil {
+		s, err := a.LookupOpt(key)
+		if err != nil {
+			return nil, err
+		}
+		result = []string{s}
+	}
+
+	return result, nil
+}
+
+// GetOptF64 returns the value for the supplied key as a float64. It exits(1)
+// if the option is not found or is not a number.
+func (a *Args) GetOptF64(key string) float64 {
+	f, err := a.LookupOptF64(key)
+	exitOn(err)
+	return f
+}
+
+// GetOptB returns the value for the supplied key as a boolean (see
+// LookupOptB). It exits(1) if the option is not found or is not a boolean.
+func (a *Args) GetOptB(key string) bool {
+	b, err := a.LookupOptB(key)
+	exitOn(err)
+	return b
+}
+
+// GetOptDuration returns the value for the supplied key as a time.Duration.
+// It exits(1) if the option is not found or is not a duration.
+func (a *Args) GetOptDuration(key string) time.Duration {
+	d, err := a.LookupOptDuration(key)
+	exitOn(err)
+	return d
+}
+
+// GetOptList returns the values of all occurrences of the supplied key
+// (see LookupOptList). It exits(1) if the option is not found.
+func (a *Args) GetOptList(key string) []string {
+	list, err := a.LookupOptList(key)
+	exitOn(err)
+	return list
+}
+
+// ParseB converts a string into a boolean. The accepted values are
+// (case-insensitive):
+//
+// true  => true, yes, on, 1
+// false => false, no, off, 0
+//
+// Any other value results in an error.
+func ParseB(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "yes", "on", "1":
+		return true, nil
+	case "false", "no", "off", "0":
+		return false, nil
+	}
+
+	return false, fmt.Errorf("invalid boolean: %s", s)
+}
+
+// Returns the error for a value of the wrong type
+func invalid(what, key, val string) error {
+	return fmt.Errorf("Args - Invalid %s for option %s: %s", what, key, val)
+}
@@ -0,0 +1,75 @@
+package args
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTyped(t *testing.T) {
+	assert := assert.New(t)
+
+	a := NewArgs("prog", "-i=7", "-f=2.5", "-b=Yes", "-d=1m30s", "-k=a", "-k=b", "-bad=x")
+
+	// Lookups
+	i, err := a.LookupOptI("i")
+	assert.Nil(err)
+	assert.Equal(7, i)
+
+	f, err := a.LookupOptF64("f")
+	assert.Nil(err)
+	assert.Equal(2.5, f)
+
+	b, err := a.LookupOptB("b")
+	assert.Nil(err)
+	assert.True(b)
+
+	d, err := a.LookupOptDuration("d")
+	assert.Nil(err)
+	assert.Equal(90*time.Second, d)
+
+	list, err := a.LookupOptList("k")
+	assert.Nil(err)
+	assert.Equal([]string{"a", "b"}, list)
+
+	val, err := a.Lookup(0)
+	assert.Nil(err)
+	assert.Equal("prog", val)
+
+	// Errors
+	_, err = a.Lookup(1)
+	assert.EqualError(err, "Args: Index out of range: 1  [0, 1)")
+	_, err = a.LookupOpt("missing")
+	assert.EqualError(err, "Args - Missing option: missing")
+	_, err = a.LookupOptI("bad")
+	assert.EqualError(err, "Args - Invalid integer for option bad: x")
+	_, err = a.LookupOptF64("bad")
+	assert.EqualError(err, "Args - Invalid number for option bad: x")
+	_, err = a.LookupOptB("bad")
+	assert.EqualError(err, "Args - Invalid boolean for option bad: x")
+	_, err = a.LookupOptDuration("bad")
+	assert.EqualError(err, "Args - Invalid duration for option bad: x")
+	_, err = a.LookupOptList("missing")
+	assert.NotNil(err)
+
+	// Getters
+	assert.Equal(2.5, a.GetOptF64("f"))
+	assert.True(a.GetOptB("b"))
+	assert.Equal(90*time.Second, a.GetOptDuration("d"))
+	assert.Equal([]string{"a", "b"}, a.GetOptList("k"))
+	assert.Equal("a", a.GetOptOr("k", "z"))
+	assert.Equal("z", a.GetOptOr("missing", "z"))
+
+	// Spec Defaults (And Bool Type)
+	s := NewSpec("prog")
+	s.Add(Opt{Name: "tag", Type: TypeString, Default: "x"})
+	s.Add(Opt{Name: "dry", Type: TypeBool, Default: "no"})
+	a, err = s.Load([]string{"prog"})
+	assert.Nil(err)
+	assert.Equal([]string{"x"}, a.GetOptList("tag"))
+	assert.False(a.GetOptB("dry"))
+	assert.Equal("x", a.GetOptOr("tag", "z"))
+
+	_, err = s.Load([]string{"prog", "-dry=maybe"})
+	assert.EqualError(err, "Args - Invalid value for option -dry: maybe (must be a bool)")
+}
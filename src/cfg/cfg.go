@@ -2,16 +2,35 @@ package cfg
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"github.com/enova/tokyo/src/set"
 	"github.com/mgutz/ansi"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 )
 
 type entry struct {
-	key string
-	val string
+	key   string
+	val   string
+	file  string // File in which the entry was defined
+	line  int    // Line number (starting at 1) of the entry within the file
+	layer Layer  // Layer the value came from (see Override)
+
+	secret bool   // The value was resolved from references (see resolveRefs)
+	raw    string // The value before references were resolved (if secret)
+
+	comments []string // Comment lines immediately preceding the entry
+}
+
+// Origin returns the entry's location formatted as file:line
+func (e entry) origin() string {
+	if e.file == "" {
+		return "code"
+	}
+	return where(e.file, e.line)
 }
 
 // Config holds an ordered list of entries.
@@ -22,39 +41,153 @@ type Config struct {
 	defines  map[string]string
 	stem     string
 	includes *set.S
+	watched  *set.S // Include patterns and referenced files (see Watcher)
+	index    *index
 }
 
-// New returns a new Config object constructed using the supplied filename
+// New returns a new Config object constructed using the supplied filename.
+// If the file can't be parsed it exits(1).
 func New(filename string) *Config {
+	c, err := Load(filename)
+	if err != nil {
+		exit(err.Error())
+	}
+	return c
+}
+
+// Load is like New but returns an error instead of exiting
+// if the file can't be parsed.
+func Load(filename string) (*Config, error) {
+	c, err := parse(filename)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Parses the supplied file. On failure the config is incomplete but still
+// holds the files and patterns parsed so far (so a Watcher can track them).
+func parse(filename string) (*Config, error) {
 	c := &Config{
 		defines:  make(map[string]string),
 		includes: set.NewS(),
+		watched:  set.NewS(),
 	}
 
-	c.fromFile(filename)
-	return c
+	if err := c.fromFile(filename, ""); err != nil {
+		return c, err
+	}
+
+	c.reindex()
+	return c, nil
 }
 
-func (c *Config) fromFile(filename string) {
+// FromFile parses the supplied file into the config. The argument
+// from is the location of the #INCLUDE directive (empty for the root file).
+func (c *Config) fromFile(filename, from string) error {
 
 	// Open File
 	file, err := os.Open(filename)
 	if err != nil {
-		exit("Can't open config file: " + filename + ", " + err.Error())
+		msg := "Can't open config file: " + filename + ", " + err.Error()
+		if from != "" {
+			msg += " (included at " + from + ")"
+		}
+		return errors.New(msg)
 	}
 	defer file.Close()
 
 	// Add File To Includes (To Prevent Circular inclusion)
-	c.includes.Insert(filename)
+	c.includes.Insert(canonical(filename))
+
+	// Structured Formats (YAML, JSON, TOML)
+	if decode := decoderFor(filename); decode != nil {
+		return c.fromStructured(file, decode)
+	}
 
 	// Scan
 	var prevKey string
+	var lineNum int
+	var comments []string
+	var conds blocks
+	var doc *heredoc
+
+	// Add Value (Or Append It For key+=)
+	addValue := func(key, unresolved string, lineNum int, line, at string) error {
+
+		// Resolve References (e.g. ${env:NAME})
+		val, secret, err := c.resolve(unresolved, filepath.Dir(filename))
+		if err != nil {
+			return errors.New(err.Error() + at)
+		}
+
+		// Key-Plus-Equals (Append Previous Value)
+		if strings.HasSuffix(key, "+=") {
+
+			// Confirm Key Matches Previous Key
+			key = strings.TrimSuffix(key, "+=")
+			if key != prevKey || prevKey == "" {
+				return errors.New("Config - Previous key does not match key with +=: " + line + ", " + prevKey + c.suffix() + at)
+			}
+
+			prev := &c.entries[len(c.entries)-1]
+			if secret && !prev.secret {
+				prev.raw = prev.val
+				prev.secret = true
+			}
+			if prev.secret {
+				prev.raw += " " + unresolved
+			}
+			prev.val += " " + val
+			return nil
+		}
+
+		// New-Key => Value
+		e := entry{
+			key:      key,
+			val:      val,
+			file:     filename,
+			line:     lineNum,
+			comments: comments,
+		}
+		if secret {
+			e.raw = unresolved
+			e.secret = true
+		}
+		c.entries = append(c.entries, e)
+		comments = nil
+
+		// Set Previous-Key (for +=)
+		prevKey = key
+		return nil
+	}
 
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 
 		// Read-Line
-		line := scanner.Text()
+		raw := scanner.Text()
+		line := raw
+		lineNum++
+		at := " (at " + where(filename, lineNum) + ")"
+
+		// Heredoc Lines (Verbatim) Up To The Terminator
+		if doc != nil {
+			if strings.TrimSpace(raw) != doc.end {
+				doc.lines = append(doc.lines, raw)
+				continue
+			}
+
+			d := doc
+			doc = nil
+			if d.skip {
+				continue
+			}
+			if err := addValue(d.key, strings.Join(d.lines, "\n"), d.lineNum, d.line, d.at); err != nil {
+				return err
+			}
+			continue
+		}
 
 		// Apply Defines
 		for word, definition := range c.defines {
@@ -64,6 +197,30 @@ func (c *Config) fromFile(filename string) {
 		// Tokens
 		tokens := strings.Fields(line)
 
+		// Conditional Blocks (Directives Use The Raw Tokens)
+		isCond, err := conds.directive(strings.Fields(raw), tokens, c.defines, line, at)
+		if err != nil {
+			return err
+		}
+
+		// Skip Conditional Directives
+		if isCond {
+			continue
+		}
+
+		// Heredoc (Opened Even When Inactive So Its Lines Are Skipped)
+		if len(tokens) >= 2 && !strings.HasPrefix(tokens[0], "#") {
+			if end, ok := heredocEnd(valueText(line, tokens[0])); ok {
+				doc = &heredoc{key: tokens[0], end: end, lineNum: lineNum, line: line, at: at, skip: !conds.active()}
+				continue
+			}
+		}
+
+		// Skip Inactive Lines
+		if !conds.active() {
+			continue
+		}
+
 		// Not Enough Tokens
 		if len(tokens) < 2 {
 			continue
@@ -78,7 +235,7 @@ func (c *Config) fromFile(filename string) {
 
 			// Target Must Be Wrapped In Angle Brackets: <target>
 			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
-				exit("Bad Define - Target must be surrounded by <>: " + target + ", in line: " + line)
+				return errors.New("Bad Define - Target must be surrounded by <>: " + target + ", in line: " + line + at)
 			}
 
 			definition := strings.Join(tokens[2:], " ")
@@ -94,12 +251,12 @@ func (c *Config) fromFile(filename string) {
 
 			// Target Must Be Wrapped In Angle Brackets: <target>
 			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
-				exit("Bad Define - Target must be surrounded by <>: " + target + ", in line: " + line)
+				return errors.New("Bad Define - Target must be surrounded by <>: " + target + ", in line: " + line + at)
 			}
 
 			// Must Have Three Tokens: #ENV <target> variable
 			if len(tokens) != 3 {
-				exit("Bad Environment Substitution - Target must be followed with one token (representing environment-variable name): " + target + ", in line: " + line)
+				return errors.New("Bad Environment Substitution - Target must be followed with one token (representing environment-variable name): " + target + ", in line: " + line + at)
 			}
 
 			// Get Environment-Variable's Value
@@ -108,7 +265,7 @@ func (c *Config) fromFile(filename string) {
 
 			// Value Must Be Non-Empty
 			if len(value) == 0 {
-				exit("This config requires the environment variable " + variable + " to be defined according to line: " + line)
+				return errors.New("This config requires the environment variable " + variable + " to be defined according to line: " + line + at)
 			}
 
 			// Add To Definitions
@@ -120,87 +277,40 @@ func (c *Config) fromFile(filename string) {
 		// Include Another File //
 		//////////////////////////
 
-		if tokens[0] == "#INCLUDE" {
-			inclFile := tokens[1]
-
-			// Check For Immediate Circular Inclusion
-			if c.includes.Contains(inclFile) {
-				exit("Circular or Duplicate file inclusion: " + inclFile + " found at " + filename)
-			}
-
-			// Build Config (Pass Current Includes Upward)
-			i := &Config{
-				defines:  make(map[string]string),
-				includes: c.includes.Copy(),
-			}
-
-			// Add Defines To Include
-			for k, v := range c.defines {
-				i.defines[k] = v
-			}
-
-			// Construct Include Config
-			i.fromFile(inclFile)
-
-			// Add New Files To Includes
-			c.includes = c.includes.Union(i.includes)
-
-			// Add New Entries
-			c.entries = append(c.entries, i.entries...)
-
-			// Add New Defines
-			for k, v := range i.defines {
-				c.defines[k] = v
+		if tokens[0] == "#INCLUDE" || tokens[0] == "#INCLUDE?" {
+			optional := tokens[0] == "#INCLUDE?"
+			if err := c.include(tokens[1], optional, filename, lineNum); err != nil {
+				return err
 			}
 		}
 
-		// Comment (Skip)
+		// Comment (Skip, But Keep For The Next Entry)
 		if strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		// Key
-		key := tokens[0]
-
-		// Join Remaining Tokens To Make Value (With Single-Space)
-		val := strings.Join(tokens[1:], " ")
-
-		// Key-Plus-Equals (Append Previous Value)
-		if strings.HasSuffix(key, "+=") {
-
-			// Confirm Key Matches Previous Key
-			key = strings.TrimSuffix(key, "+=")
-			if key != prevKey {
-				exit("Config - Previous key does not match key with +=: " + line + ", " + prevKey + c.suffix())
+			if !isDirective(tokens[0]) {
+				comments = append(comments, line)
 			}
-
-			c.entries[len(c.entries)-1].val += " " + val
 			continue
 		}
 
-		// New-Key => Value
-		e := entry{
-			key: tokens[0],
-			val: val,
+		// Value (Quoted Or Plain, Without Inline Comment)
+		unresolved := parseValue(valueText(line, tokens[0]))
+		if err := addValue(tokens[0], unresolved, lineNum, line, at); err != nil {
+			return err
 		}
-		c.entries = append(c.entries, e)
+	}
 
-		// Set Previous-Key (for +=)
-		prevKey = key
+	// Every Heredoc Needs Its Terminator
+	if doc != nil {
+		return errors.New("Config - Missing heredoc terminator " + doc.end + " for key " + doc.key + doc.at)
 	}
+
+	// Every #IF Needs An #ENDIF (In The Same File)
+	return conds.close()
 }
 
 // Has returns true if the key occurs.
 func (c *Config) Has(key ...string) bool {
-	joined := join(key...)
-
-	for _, e := range c.entries {
-		if e.key == joined {
-			return true
-		}
-	}
-
-	return false
+	return len(c.positions(join(key...))) > 0
 }
 
 // Is returns true if the value for the given key matches the supplied value.
@@ -216,59 +326,74 @@ func (c *Config) Is(key, val string) bool {
 // does not exist it exits(1). If there are multiple
 // occurrences of the key it exits(1)
 func (c *Config) Get(key ...string) string {
+	return c.get(key...).val
+}
+
+// Origin returns the file and line number where the given key
+// was defined. If the key does not exist it exits(1). If there
+// are multiple occurrences of the key it exits(1)
+func (c *Config) Origin(key ...string) (string, int) {
+	e := c.get(key...)
+	return e.file, e.line
+}
+
+// OriginN returns the file and line number of the Nth occurrence
+// of the given key.
+func (c *Config) OriginN(i int, key ...string) (string, int) {
+	e := c.getN(i, key...)
+	return e.file, e.line
+}
+
+// Returns the single entry for the given key (exits on missing or duplicate keys)
+func (c *Config) get(key ...string) entry {
 	joined := join(key...)
-	vals := c.vals(key...)
+	found := c.find(key...)
 
-	if len(vals) == 0 {
+	if len(found) == 0 {
 		exit("Config - Missing key: " + joined + c.suffix())
 	}
 
-	if len(vals) > 1 {
-		exit("Config - Duplicate key: " + joined + c.suffix())
+	if len(found) > 1 {
+		origins := make([]string, len(found))
+		for i, e := range found {
+			origins[i] = e.origin()
+		}
+		exit("Config - Duplicate key: " + joined + c.suffix() + " (at " + strings.Join(origins, ", ") + ")")
 	}
 
-	return vals[0]
+	return found[0]
 }
 
 // GetN returns the Nth value for the given key.
 func (c *Config) GetN(i int, key ...string) string {
+	return c.getN(i, key...).val
+}
+
+// Returns the Nth entry for the given key (exits if out-of-range)
+func (c *Config) getN(i int, key ...string) entry {
 	joined := join(key...)
-	vals := c.vals(key...)
+	found := c.find(key...)
 
 	// Out-Of-Range
-	if i < 0 || i >= len(vals) {
-		msg := fmt.Sprintf("Config - Index out-of-range for key %s: %d (>= %d or negtive)", joined, i, len(vals))
+	if i < 0 || i >= len(found) {
+		msg := fmt.Sprintf("Config - Index out-of-range for key %s: %d (>= %d or negtive)", joined, i, len(found))
 		exit(msg + c.suffix())
 	}
 
-	return vals[i]
+	return found[i]
 }
 
 // Size returns the number of occurrences of the supplied key.
 func (c *Config) Size(key ...string) int {
-	var result int
-	joined := join(key...)
-
-	for _, e := range c.entries {
-		if e.key == joined {
-			result++
-		}
-	}
-
-	return result
+	return len(c.positions(join(key...)))
 }
 
-// Returns a list of strings containing all values that occur
-// for the given key. The order is maintained and agrees with that
-// of the file.
-func (c *Config) vals(key ...string) []string {
-	var result []string
-	joined := join(key...)
+// Returns all entries for the given key (in file order)
+func (c *Config) find(key ...string) []entry {
+	var result []entry
 
-	for _, e := range c.entries {
-		if e.key == joined {
-			result = append(result, e.val)
-		}
+	for _, pos := range c.positions(join(key...)) {
+		result = append(result, c.entries[pos])
 	}
 
 	return result
@@ -291,34 +416,17 @@ func (c *Config) vals(key ...string) []string {
 //
 func (c *Config) SubKeys(stems ...string) []string {
 	result := make([]string, 0, 1)
-	prefix := join(stems...) + "."
-	added := set.NewS()
-
-	for _, e := range c.entries {
-
-		// Found Prefix
-		if strings.HasPrefix(e.key, prefix) {
-
-			// Remove Prefix and Split Suffix
-			suffix := strings.TrimPrefix(e.key, prefix)
-			pieces := strings.Split(suffix, ".")
 
-			// Add Sub-Key
-			if len(pieces) > 0 {
-				subKey := pieces[0]
-
-				if !added.Contains(subKey) {
-					result = append(result, subKey)
-					added.Insert(subKey)
-				}
-			}
-		}
+	if n := c.lookup(join(stems...)); n != nil {
+		result = append(result, n.order...)
 	}
 
 	return result
 }
 
-// HasSubKey ...
+// HasSubKey returns true if the final argument is a sub-key of the prefix
+// formed by the preceding arguments (see SubKeys). Segments are matched
+// exactly, so HasSubKey("db", "u") does not match the key db.us.
 func (c *Config) HasSubKey(stems ...string) bool {
 
 	// Sub-Key requires prefix and sub-key
@@ -326,28 +434,13 @@ func (c *Config) HasSubKey(stems ...string) bool {
 		return false
 	}
 
-	prefix := join(stems...)
-
-	for _, e := range c.entries {
-		if strings.HasPrefix(e.key, prefix) {
-			return true
-		}
-	}
-
-	return false
+	return c.lookup(join(stems...)) != nil
 }
 
 // HasPrefix ...
 func (c *Config) HasPrefix(stems ...string) bool {
-	prefix := join(stems...) + "."
-
-	for _, e := range c.entries {
-		if strings.HasPrefix(e.key, prefix) {
-			return true
-		}
-	}
-
-	return false
+	n := c.lookup(join(stems...))
+	return n != nil && len(n.children) > 0
 }
 
 // Descend returns a newly created Config containing
@@ -377,26 +470,135 @@ func (c *Config) Descend(stems ...string) *Config {
 	result := Config{}
 	prefix := join(stems...) + "."
 
-	for _, e := range c.entries {
+	// Entries At Or Below The Prefix
+	var positions []int
+	if n := c.lookup(join(stems...)); n != nil {
+		positions = n.entries
+	}
+
+	for _, pos := range positions {
+		e := c.entries[pos]
 
 		if strings.HasPrefix(e.key, prefix) {
 
 			// Descended Entry (Remove Prefix)
-			d := entry{
-				key: strings.TrimPrefix(e.key, prefix),
-				val: e.val,
-			}
+			d := e
+			d.key = strings.TrimPrefix(e.key, prefix)
 
 			// Add Descended Entry to Result
 			result.entries = append(result.entries, d)
 		}
 	}
+	result.reindex()
 
 	// Expand Prefix
 	result.stem += prefix
 	return &result
 }
 
+// Include parses the files matching the supplied pattern (in sorted order)
+// and adds their entries and defines. Relative patterns are resolved against
+// the directory of the including file. Unless the include is optional, the
+// pattern must match at least one file.
+func (c *Config) include(pattern string, optional bool, filename string, lineNum int) error {
+	from := where(filename, lineNum)
+
+	// Resolve Relative To The Including File
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(filename), pattern)
+	}
+
+	// Watch The Pattern (Files May Be Created Later)
+	c.watched.Insert(pattern)
+
+	// Expand Glob (A Plain Filename Matches Itself If It Exists)
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return errors.New("Bad Include - Invalid pattern: " + pattern + " (at " + from + ")")
+	}
+	sort.Strings(matches)
+
+	// No Matches
+	if len(matches) == 0 {
+		if optional {
+			return nil
+		}
+
+		if !strings.ContainsAny(pattern, "*?[") {
+			return errors.New("Can't open config file: " + pattern + " (included at " + from + ")")
+		}
+		return errors.New("Bad Include - No files match: " + pattern + " (at " + from + ")")
+	}
+
+	for _, inclFile := range matches {
+
+		// Check For Immediate Circular Inclusion
+		if c.includes.Contains(canonical(inclFile)) {
+			return errors.New("Circular or Duplicate file inclusion: " + inclFile + " found at " + from)
+		}
+
+		// Build Config (Pass Current Includes Upward)
+		i := &Config{
+			defines:  make(map[string]string),
+			includes: c.includes.Copy(),
+			watched:  c.watched,
+		}
+
+		// Add Defines To Include
+		for k, v := range c.defines {
+			i.defines[k] = v
+		}
+
+		// Construct Include Config (Keeping Its Files Even On Failure)
+		err := i.fromFile(inclFile, from)
+		c.includes = c.includes.Union(i.includes)
+		if err != nil {
+			return err
+		}
+
+		// Add New Entries
+		c.entries = append(c.entries, i.entries...)
+
+		// Add New Defines
+		for k, v := range i.defines {
+			c.defines[k] = v
+		}
+	}
+
+	return nil
+}
+
+// Canonical returns the absolute path of a file with symbolic links resolved
+// (if possible). It is used to detect circular inclusions.
+func canonical(filename string) string {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return filepath.Clean(filename)
+	}
+
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// IsDirective returns true if the token is one of the directives (e.g. #DEFINE)
+func isDirective(token string) bool {
+	switch token {
+	case "#DEFINE", "#ENV", "#INCLUDE", "#INCLUDE?":
+		return true
+	}
+	return false
+}
+
+// Where formats a file location as file:line
+func where(filename string, line int) string {
+	if line == 0 {
+		return filename
+	}
+	return fmt.Sprintf("%s:%d", filename, line)
+}
+
 // Joins together segments with a "."
 func join(segments ...string) string {
 	return strings.Join(segments, ".")
@@ -6,7 +6,9 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 // Code holds a block of code
@@ -35,6 +37,12 @@ func (c *Code) Text() string {
 
 // Run executes the code and returns the error
 func (c *Code) Run() error {
+	_, err := c.Output()
+	return err
+}
+
+// Output executes the code and returns its output and the error
+func (c *Code) Output() (string, error) {
 	var result error
 
 	// Header
@@ -70,7 +78,7 @@ func (c *Code) Run() error {
 	cmd = "rm -rf test_code"
 	exec.Command("bash", "-c", cmd).Output()
 
-	return result
+	return string(output), result
 }
 
 ////////////
@@ -147,6 +155,9 @@ func TestCfg(t *testing.T) {
 	assert.False(cfg.HasSubKey("db", "ca"))
 	assert.True(cfg.HasSubKey("db", "us", "name")) // "name" is a sub-key of "db.us"
 	assert.False(cfg.HasSubKey("db"))              // Not enough arguments - Needs a prefix and a sub-key.
+	assert.False(cfg.HasSubKey("db", "u"))         // Segments must match exactly
+	assert.False(cfg.HasSubKey("db", "us", "nam"))
+	assert.False(cfg.HasSubKey("d", "us"))
 
 	// HasPrefix
 	assert.True(cfg.HasPrefix("db"))
@@ -193,6 +204,178 @@ func TestCfg(t *testing.T) {
 
 	// Environment-Variable Substitution (#ENV)
 	assert.Equal(cfg.Get("message"), "all-good")
+
+	// Origin
+	file, line := cfg.Origin("db", "us", "user")
+	assert.Equal("test/test.cfg", file)
+	assert.Equal(5, line)
+
+	file, line = cfg.Origin("sentence") // Continuation lines keep the original line
+	assert.Equal("test/test.cfg", file)
+	assert.Equal(21, line)
+
+	file, line = cfg.Origin("height") // Included file
+	assert.Equal("test/parent.cfg", file)
+	assert.Equal(1, line)
+
+	file, line = cfg.OriginN(2, "email")
+	assert.Equal("test/test.cfg", file)
+	assert.Equal(17, line)
+
+	file, line = d.Origin("uk", "port") // Descended config keeps origins
+	assert.Equal("test/test.cfg", file)
+	assert.Equal(13, line)
+}
+
+func TestTyped(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := New("test/typed.cfg")
+
+	// Typed Getters
+	assert.Equal(8, cfg.GetI("threads"))
+	assert.Equal(0.75, cfg.GetF64("ratio"))
+	assert.True(cfg.GetB("verbose"))
+	assert.False(cfg.GetB("quiet"))
+	assert.Equal(90*time.Second, cfg.GetDuration("timeout"))
+	assert.Equal([]string{"alpha", "beta", "gamma"}, cfg.GetList("hosts"))
+
+	// Defaults (Missing Keys)
+	assert.Equal("none", cfg.GetOr("none", "missing"))
+	assert.Equal("8", cfg.GetOr("none", "threads"))
+	assert.Equal(4, cfg.GetIOr(4, "missing"))
+	assert.Equal(8, cfg.GetIOr(4, "threads"))
+	assert.Equal(1.5, cfg.GetF64Or(1.5, "missing"))
+	assert.True(cfg.GetBOr(true, "missing"))
+	assert.True(cfg.GetBOr(false, "verbose"))
+	assert.Equal(time.Second, cfg.GetDurationOr(time.Second, "missing"))
+	assert.Equal([]string{"x"}, cfg.GetListOr([]string{"x"}, "missing"))
+
+	// ParseB
+	for _, s := range []string{"true", "True", "YES", "on", "1"} {
+		b, err := ParseB(s)
+		assert.Nil(err, s)
+		assert.True(b, s)
+	}
+
+	for _, s := range []string{"false", "False", "NO", "off", "0"} {
+		b, err := ParseB(s)
+		assert.Nil(err, s)
+		assert.False(b, s)
+	}
+
+	_, err := ParseB("maybe")
+	assert.NotNil(err)
+}
+
+func TestIncludes(t *testing.T) {
+	assert := assert.New(t)
+	assert.Nil(os.Setenv("CFG_TEST", "all-good"))
+
+	// Includes Are Relative To The Including File (Not The Working Directory)
+	abs, err := filepath.Abs("test/test.cfg")
+	assert.Nil(err)
+
+	cfg, err := Load(abs)
+	assert.Nil(err)
+	assert.Equal("36", cfg.Get("height"))
+	assert.Equal("60", cfg.Get("mass"))
+
+	// Globs (Sorted) And Optional Includes
+	cfg = New("test/glob.cfg")
+	assert.Equal(2, cfg.Size("zone"))
+	assert.Equal("a", cfg.GetN(0, "zone"))
+	assert.Equal("b", cfg.GetN(1, "zone"))
+	assert.Equal("glob", cfg.Get("name"))
+
+	file, _ := cfg.Origin("shared")
+	assert.Equal(filepath.Join("test", "conf.d", "a.cfg"), file)
+
+	// Missing Include
+	_, err = Load("test/bad/missing_include.cfg")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "test/bad/missing_include.cfg:3")
+
+	// Circular Include
+	_, err = Load("test/bad/circular.cfg")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "Circular")
+}
+
+func TestConditionals(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := New("test/cond/cond.cfg")
+
+	// #IF, #ELSE (Nested)
+	assert.Equal("prod.firm.com", cfg.Get("db.host"))
+	assert.Equal("1", cfg.Get("db.zone"))
+	assert.Equal("false", cfg.Get("debug"))
+
+	// Defines In Inactive Blocks Are Skipped
+	assert.False(cfg.Has("never.defined"))
+
+	// #IFDEF
+	assert.True(cfg.Has("env.defined"))
+
+	// Included File
+	assert.Equal("red", cfg.Get("color"))
+
+	// Unbalanced Blocks
+	_, err := Load("test/bad/unbalanced_if.cfg")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "Missing #ENDIF")
+	assert.Contains(err.Error(), "test/bad/unbalanced_if.cfg:5")
+
+	_, err = Load("test/bad/unbalanced_endif.cfg")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "#ENDIF without #IF")
+	assert.Contains(err.Error(), "test/bad/unbalanced_endif.cfg:4")
+
+	_, err = Load("test/bad/unbalanced_include.cfg")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "test/bad/unbalanced_if.cfg:5")
+
+	_, err = Load("test/bad/bad_if.cfg")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "Bad #IF")
+}
+
+func TestQuoted(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := New("test/quoted.cfg")
+
+	// Quoted Values
+	assert.Equal("  two  spaces  ", cfg.Get("padded"))
+	assert.Equal("a\tb", cfg.Get("tabbed"))
+	assert.Equal("red # not a comment", cfg.Get("hashed"))
+	assert.Equal(`say "hi"\n`, cfg.Get("escaped"))
+	assert.Equal("", cfg.Get("empty"))
+
+	// Inline Comments
+	assert.Equal("blue", cfg.Get("color"))
+	assert.Equal("#ff0000", cfg.Get("hex"))
+	assert.Equal("fixed a#b #12", cfg.Get("issue"))
+
+	// Heredoc (Skipped In Inactive Blocks)
+	assert.Equal("Dear Bruce,\n\n  # Not a comment", cfg.Get("message"))
+	file, line := cfg.Origin("message")
+	assert.Equal("test/quoted.cfg", file)
+	assert.Equal(14, line)
+	assert.False(cfg.Has("skipped"))
+	assert.Equal("done", cfg.Get("after"))
+
+	// Not Quoted Values (Plain, As Before)
+	assert.Equal(`"Big" thing`, cfg.Get("title"))
+	assert.Equal(`"unterminated quote`, cfg.Get("open"))
+	assert.Equal(`"C:\dir"`, cfg.Get("path"))
+	assert.Equal("# only a comment", cfg.Get("commented"))
+
+	// Errors
+	_, err := Load("test/bad/bad_heredoc.cfg")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "Missing heredoc terminator END for key key (at test/bad/bad_heredoc.cfg:1)")
 }
 
 // Test Exit-Points
@@ -222,7 +405,7 @@ func TestExit(t *testing.T) {
 	assert.NotNil(code.Run(), "Bad ENV missing-variable name in config")
 
 	code.Reset()
-	code.Add(`cfg.New("test/bad_circular.cfg")`)
+	code.Add(`cfg.New("test/bad/circular.cfg")`)
 	assert.NotNil(code.Run(), "Circular file inclusion")
 
 	code.Reset()
@@ -255,4 +438,27 @@ func TestExit(t *testing.T) {
 	code.Add(`cfg := cfg.New("test/bad/duplicate_key.cfg")`)
 	code.Add(`cfg.GetN(2, "fruits")`)
 	assert.NotNil(code.Run(), "Bad call to GetN(), out of range")
+
+	code.Reset()
+	code.Add(`cfg := cfg.New("test/typed.cfg")`)
+	code.Add(`cfg.GetI("bad.int")`)
+	assert.NotNil(code.Run(), "Bad call to GetI(), not an integer")
+
+	code.Reset()
+	code.Add(`cfg := cfg.New("test/typed.cfg")`)
+	code.Add(`cfg.GetB("bad.bool")`)
+	assert.NotNil(code.Run(), "Bad call to GetB(), not a boolean")
+
+	code.Reset()
+	code.Add(`cfg := cfg.New("test/typed.cfg")`)
+	code.Add(`cfg.GetIOr(0, "dup")`)
+	assert.NotNil(code.Run(), "Can't call GetIOr() when there are duplicate keys")
+
+	code.Reset()
+	code.Add(`cfg := cfg.New("test/secret/typed.cfg")`)
+	code.Add(`cfg.GetI("db.port")`)
+	output, err := code.Output()
+	assert.NotNil(err, "Bad call to GetI(), not an integer (secret)")
+	assert.Contains(output, "Invalid integer for key db.port: ${file:db_password}")
+	assert.NotContains(output, "s3cr3t")
 }
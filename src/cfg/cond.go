@@ -0,0 +1,129 @@
+package cfg
+
+import (
+	"errors"
+	"strings"
+)
+
+// Block is a single #IF/#IFDEF block. A block is active if the lines in its
+// current branch are to be used.
+type block struct {
+	active  bool   // Current branch is used
+	taken   bool   // The #IF branch was used (so #ELSE is not)
+	parent  bool   // Enclosing block is active
+	hasElse bool   // #ELSE has been seen
+	at      string // Location of the opening directive
+}
+
+// Blocks is the stack of open conditional blocks within a single file.
+// Blocks can be nested but can't span files.
+type blocks struct {
+	stack []block
+}
+
+// Active returns true if lines at the current position are to be used
+func (b *blocks) active() bool {
+	if len(b.stack) == 0 {
+		return true
+	}
+	return b.stack[len(b.stack)-1].active
+}
+
+// Directive processes a conditional directive. It returns true if the line
+// was a conditional directive. The raw tokens are those read from the file,
+// the tokens are those after defines have been applied.
+//
+// #IF <define> == value
+// #IF <define> != value
+// #IFDEF <define>
+// #ELSE
+// #ENDIF
+func (b *blocks) directive(raw, tokens []string, defines map[string]string, line, at string) (bool, error) {
+	if len(raw) == 0 {
+		return false, nil
+	}
+
+	switch raw[0] {
+
+	case "#IF":
+		cond, err := evalIf(tokens)
+		if err != nil {
+			return true, errors.New(err.Error() + ", in line: " + line + at)
+		}
+		b.open(cond, at)
+
+	case "#IFDEF":
+
+		// Must Have Two Tokens: #IFDEF <target>
+		if len(raw) != 2 || !strings.HasPrefix(raw[1], "<") || !strings.HasSuffix(raw[1], ">") {
+			return true, errors.New("Bad #IFDEF - Must be followed by one target surrounded by <>, in line: " + line + at)
+		}
+		_, defined := defines[raw[1]]
+		b.open(defined, at)
+
+	case "#ELSE":
+		if len(b.stack) == 0 {
+			return true, errors.New("Config - #ELSE without #IF" + at)
+		}
+
+		top := &b.stack[len(b.stack)-1]
+		if top.hasElse {
+			return true, errors.New("Config - Duplicate #ELSE" + at + " for the #IF" + top.at)
+		}
+
+		top.hasElse = true
+		top.active = top.parent && !top.taken
+
+	case "#ENDIF":
+		if len(b.stack) == 0 {
+			return true, errors.New("Config - #ENDIF without #IF" + at)
+		}
+		b.stack = b.stack[:len(b.stack)-1]
+
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// Close returns an error if any block is still open (at the end of a file)
+func (b *blocks) close() error {
+	if len(b.stack) == 0 {
+		return nil
+	}
+
+	top := b.stack[len(b.stack)-1]
+	return errors.New("Config - Missing #ENDIF for #IF" + top.at)
+}
+
+// Open a new block
+func (b *blocks) open(cond bool, at string) {
+	parent := b.active()
+	b.stack = append(b.stack, block{
+		active: parent && cond,
+		taken:  cond,
+		parent: parent,
+		at:     at,
+	})
+}
+
+// Evaluates: #IF lhs == rhs (or !=)
+func evalIf(tokens []string) (bool, error) {
+	for i, t := range tokens {
+		if t != "==" && t != "!=" {
+			continue
+		}
+
+		// Both Sides Must Be Non-Empty
+		if i < 2 || i == len(tokens)-1 {
+			break
+		}
+
+		lhs := strings.Join(tokens[1:i], " ")
+		rhs := strings.Join(tokens[i+1:], " ")
+		return (lhs == rhs) == (t == "=="), nil
+	}
+
+	return false, errors.New("Bad #IF - Expected: #IF <define> == value (or !=)")
+}
@@ -0,0 +1,112 @@
+package cfg
+
+// DiffKind describes how a key differs between two configs
+type DiffKind string
+
+// Kinds Of Differences
+const (
+	DiffAdded   DiffKind = "added"   // The key only occurs in the second config
+	DiffRemoved DiffKind = "removed" // The key only occurs in the first config
+	DiffChanged DiffKind = "changed" // The key's values differ
+)
+
+// Difference describes a single key that differs between two configs.
+// Values resolved from references (e.g. ${file:...}) are reported
+// unresolved so that secrets aren't exposed.
+type Difference struct {
+	Key  string   `json:"key"`
+	Kind DiffKind `json:"kind"`
+	Old  []string `json:"old,omitempty"` // Values in the first config (in order)
+	New  []string `json:"new,omitempty"` // Values in the second config (in order)
+}
+
+// Diff compares the entries of two configs (after includes, defines and
+// references have been applied). A repeated key is changed if its list
+// of values differs, including their order. Removed and changed keys are
+// listed in the order of the first config, followed by added keys in the
+// order of the second config. Identical configs have no differences.
+func Diff(a, b *Config) []Difference {
+	var result []Difference
+	aKeys, aVals := groupEntries(a)
+	bKeys, bVals := groupEntries(b)
+
+	// Removed Or Changed
+	for _, k := range aKeys {
+		vals, ok := bVals[k]
+		switch {
+		case !ok:
+			result = append(result, Difference{k, DiffRemoved, shown(aVals[k]), nil})
+		case !sameVals(aVals[k], vals):
+			result = append(result, Difference{k, DiffChanged, shown(aVals[k]), shown(vals)})
+		}
+	}
+
+	// Added
+	for _, k := range bKeys {
+		if _, ok := aVals[k]; !ok {
+			result = append(result, Difference{k, DiffAdded, nil, shown(bVals[k])})
+		}
+	}
+
+	return result
+}
+
+// Summarizes the differences between two configs (see Diff)
+func diffEntries(a, b *Config) *Changes {
+	result := &Changes{}
+
+	for _, d := range Diff(a, b) {
+		switch d.Kind {
+		case DiffAdded:
+			result.Added = append(result.Added, d.Key)
+		case DiffRemoved:
+			result.Removed = append(result.Removed, d.Key)
+		case DiffChanged:
+			result.Modified = append(result.Modified, d.Key)
+		}
+	}
+
+	return result
+}
+
+// Returns the distinct keys (in order) and all entries for each key
+func groupEntries(c *Config) ([]string, map[string][]entry) {
+	var keys []string
+	vals := make(map[string][]entry)
+
+	for _, e := range c.entries {
+		if _, ok := vals[e.key]; !ok {
+			keys = append(keys, e.key)
+		}
+		vals[e.key] = append(vals[e.key], e)
+	}
+
+	return keys, vals
+}
+
+// Returns true if both lists of entries have identical values (including order)
+func sameVals(a, b []entry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].val != b[i].val {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns the values of the entries (unresolved for secrets)
+func shown(entries []entry) []string {
+	result := make([]string, len(entries))
+	for i, e := range entries {
+		result[i] = e.val
+		if e.secret {
+			result[i] = e.raw
+		}
+	}
+	return result
+}
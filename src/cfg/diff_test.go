@@ -0,0 +1,45 @@
+package cfg
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	assert := assert.New(t)
+
+	a := NewEmpty()
+	a.Add("name", "bruce")
+	a.Add("color", "red")
+	a.Add("email", "a@firm.com")
+	a.Add("email", "b@firm.com")
+	a.Add("size", "10")
+
+	b := NewEmpty()
+	b.Add("name", "bruce")
+	b.Add("shape", "square")
+	b.Add("email", "b@firm.com")
+	b.Add("email", "a@firm.com")
+	b.Add("size", "12")
+
+	assert.Empty(Diff(a, a))
+	assert.Equal([]Difference{
+		{"color", DiffRemoved, []string{"red"}, nil},
+		{"email", DiffChanged, []string{"a@firm.com", "b@firm.com"}, []string{"b@firm.com", "a@firm.com"}}, // Order matters
+		{"size", DiffChanged, []string{"10"}, []string{"12"}},
+		{"shape", DiffAdded, nil, []string{"square"}},
+	}, Diff(a, b))
+
+	// Secrets Are Compared Resolved But Not Shown
+	assert.Nil(os.Setenv("CFG_TEST_USER", "bruce"))
+	defer os.Unsetenv("CFG_TEST_USER")
+
+	c := New("test/secret/secret.cfg")
+	e := New("test/secret/secret.cfg")
+	e.entries[1].val = "changed"
+	assert.Equal([]Difference{
+		{"db.password", DiffChanged, []string{"${file:db_password}"}, []string{"${file:db_password}"}},
+	}, Diff(c, e))
+}
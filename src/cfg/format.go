@@ -0,0 +1,297 @@
+package cfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// A decoder flattens a structured document into key-value pairs
+type decoder func(body []byte) ([]pair, error)
+
+// Pair is a flattened key-value pair
+type pair struct {
+	key string
+	val string
+}
+
+// Returns the decoder for structured files (by extension), else nil
+func decoderFor(filename string) decoder {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yml", ".yaml":
+		return decodeYAML
+	case ".json":
+		return decodeJSON
+	case ".toml":
+		return decodeTOML
+	}
+	return nil
+}
+
+// FromStructured adds the flattened entries of a structured (YAML, JSON
+// or TOML) file. Nested keys are joined with dots and each element of an
+// array becomes an occurrence of the same key. Null and empty values are
+// skipped. References (e.g. ${env:NAME}) are resolved as in config files.
+func (c *Config) fromStructured(file *os.File, decode decoder) error {
+	filename := file.Name()
+
+	body, err := ioutil.ReadAll(file)
+	if err != nil {
+		return errors.New("Can't read config file: " + filename + ", " + err.Error())
+	}
+
+	pairs, err := decode(body)
+	if err != nil {
+		return errors.New("Can't parse config file: " + filename + ", " + err.Error())
+	}
+
+	for _, p := range pairs {
+
+		// Keys Must Be Single Tokens
+		if len(strings.Fields(p.key)) != 1 || p.key != strings.TrimSpace(p.key) {
+			return errors.New("Bad Key - Keys can't contain whitespace: '" + p.key + "' (in " + filename + ")")
+		}
+
+		val, secret, err := c.resolve(p.val, filepath.Dir(filename))
+		if err != nil {
+			return errors.New(err.Error() + " (in " + filename + ")")
+		}
+
+		e := entry{
+			key:  p.key,
+			val:  val,
+			file: filename,
+		}
+		if secret {
+			e.raw = p.val
+			e.secret = true
+		}
+		c.entries = append(c.entries, e)
+	}
+
+	return nil
+}
+
+// YAML (Maps Keep Their Order)
+func decodeYAML(body []byte) ([]pair, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+
+	var result []pair
+	err := flatten("", doc, nil, &result)
+	return result, err
+}
+
+// JSON (Maps Keep Their Order)
+func decodeJSON(body []byte) ([]pair, error) {
+	var result []pair
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	// Top-Level Must Be An Object
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('{') {
+		return nil, errors.New("top-level value must be an object")
+	}
+
+	if err := flattenJSONObject(dec, "", &result); err != nil {
+		return nil, err
+	}
+
+	// Nothing May Follow
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after top-level object")
+	}
+
+	return result, nil
+}
+
+// TOML (Tables Keep Their Order)
+func decodeTOML(body []byte) ([]pair, error) {
+	var doc map[string]interface{}
+	meta, err := toml.Decode(string(body), &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Order Of Definition
+	order := make(map[string]int)
+	for i, k := range meta.Keys() {
+		joined := strings.Join(k, ".")
+		if _, ok := order[joined]; !ok {
+			order[joined] = i
+		}
+	}
+
+	var result []pair
+	err = flatten("", doc, order, &result)
+	return result, err
+}
+
+// Flattens a JSON object (the opening brace has been consumed)
+func flattenJSONObject(dec *json.Decoder, prefix string, pairs *[]pair) error {
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		if err := flattenJSONValue(dec, joinKey(prefix, tok.(string)), pairs); err != nil {
+			return err
+		}
+	}
+
+	// Closing Brace
+	_, err := dec.Token()
+	return err
+}
+
+// Flattens a JSON value
+func flattenJSONValue(dec *json.Decoder, key string, pairs *[]pair) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+
+		// Object
+		if t == '{' {
+			return flattenJSONObject(dec, key, pairs)
+		}
+
+		// Array (Repeated Key)
+		for dec.More() {
+			if err := flattenJSONValue(dec, key, pairs); err != nil {
+				return err
+			}
+		}
+		_, err := dec.Token()
+		return err
+
+	case nil:
+		return nil
+
+	default:
+		addPair(key, fmt.Sprint(t), pairs)
+		return nil
+	}
+}
+
+// Flattens a decoded YAML or TOML value. Unordered maps are sorted using
+// order (keyed by the full dotted key) and then alphabetically.
+func flatten(prefix string, v interface{}, order map[string]int, pairs *[]pair) error {
+	switch t := v.(type) {
+
+	// Ordered Map (YAML)
+	case yaml.MapSlice:
+		for _, item := range t {
+			if err := flatten(joinKey(prefix, fmt.Sprint(item.Key)), item.Value, order, pairs); err != nil {
+				return err
+			}
+		}
+
+	// Unordered Maps
+	case map[string]interface{}:
+		for _, k := range sortKeys(prefix, t, order) {
+			if err := flatten(joinKey(prefix, k), t[k], order, pairs); err != nil {
+				return err
+			}
+		}
+
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			m[fmt.Sprint(k)] = val
+		}
+		return flatten(prefix, m, order, pairs)
+
+	// Arrays (Repeated Key)
+	case []interface{}:
+		for _, item := range t {
+			if err := flatten(prefix, item, order, pairs); err != nil {
+				return err
+			}
+		}
+
+	case []map[string]interface{}:
+		for _, item := range t {
+			if err := flatten(prefix, item, order, pairs); err != nil {
+				return err
+			}
+		}
+
+	// Scalars
+	case nil:
+	case string:
+		addPair(prefix, t, pairs)
+	case float64:
+		addPair(prefix, strconv.FormatFloat(t, 'f', -1, 64), pairs)
+	case time.Time:
+		addPair(prefix, t.Format(time.RFC3339), pairs)
+	default:
+		addPair(prefix, fmt.Sprint(t), pairs)
+	}
+
+	return nil
+}
+
+// Returns the keys of a map sorted by order of definition (then alphabetically)
+func sortKeys(prefix string, m map[string]interface{}, order map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	rank := func(k string) int {
+		if i, ok := order[joinKey(prefix, k)]; ok {
+			return i
+		}
+		return len(order)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := rank(keys[i]), rank(keys[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return keys[i] < keys[j]
+	})
+
+	return keys
+}
+
+// Adds a pair (skipping empty keys and values)
+func addPair(key, val string, pairs *[]pair) {
+	if key == "" || val == "" {
+		return
+	}
+	*pairs = append(*pairs, pair{key, val})
+}
+
+// Joins a prefix and a key with a dot
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,52 @@
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormats(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, filename := range []string{"test/format/app.yml", "test/format/app.json", "test/format/app.toml"} {
+		c, err := Load(filename)
+		assert.Nil(err, filename)
+
+		// Scalars
+		assert.Equal("inventory", c.Get("name"), filename)
+		assert.Equal(8, c.GetI("threads"), filename)
+		assert.Equal(0.75, c.GetF64("ratio"), filename)
+		assert.False(c.GetB("Alert", "Sentry", "Use"), filename)
+		assert.False(c.Has("nothing"), filename)
+
+		// Nested Maps (In Order)
+		assert.Equal([]string{"us", "uk"}, c.SubKeys("db"), filename)
+		assert.Equal("10.144.1.2", c.Get("db", "uk", "host"), filename)
+		assert.Equal(1111, c.Descend("db", "us").GetI("port"), filename)
+		assert.True(c.HasPrefix("db", "uk"), filename)
+
+		// Arrays (Repeated Keys)
+		assert.Equal(2, c.Size("email"), filename)
+		assert.Equal("billing@firm.com", c.GetN(1, "email"), filename)
+		assert.Equal("city Tokyo", c.GetN(1, "Alert.Sentry.Tag"), filename)
+
+		// Origin (No Line Numbers)
+		file, line := c.Origin("name")
+		assert.Equal(filename, file)
+		assert.Equal(0, line)
+	}
+
+	// Included By Extension
+	c := New("test/format/main.cfg")
+	assert.Equal("10.144.1.1", c.Get("db.us.host"))
+	assert.True(c.GetB("extra"))
+
+	// Bad Files
+	_, err := Load("test/bad/bad_json.json")
+	assert.NotNil(err)
+
+	_, err = Load("test/bad/array.json")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "must be an object")
+}
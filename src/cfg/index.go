@@ -0,0 +1,185 @@
+package cfg
+
+import (
+	"sort"
+	"strings"
+)
+
+// Index provides fast lookups of entries by key and by prefix. It maps
+// each key to the positions of its entries and holds a trie of key
+// segments. The index is updated whenever entries are added or removed
+// (see insert and remove) so lookups never modify the config.
+type index struct {
+	keys map[string][]int // Key => Positions of its entries (in order)
+	root *trie            // Trie of key segments
+}
+
+// Trie is a single segment within the index
+type trie struct {
+	children map[string]*trie
+	order    []string // Child segments (in order of first appearance)
+	entries  []int    // Positions of all entries whose keys are at or below this node
+}
+
+func newTrie() *trie {
+	return &trie{children: make(map[string]*trie)}
+}
+
+// Reindex rebuilds the index from scratch
+func (c *Config) reindex() {
+	c.index = &index{
+		keys: make(map[string][]int, len(c.entries)),
+		root: newTrie(),
+	}
+
+	for i := range c.entries {
+		c.index.insert(c.entries[i].key, i)
+	}
+}
+
+// Insert adds the entry at the given position
+func (x *index) insert(key string, pos int) {
+	x.keys[key] = append(x.keys[key], pos)
+
+	n := x.root
+	n.entries = append(n.entries, pos)
+
+	for _, segment := range strings.Split(key, ".") {
+		child, ok := n.children[segment]
+		if !ok {
+			child = newTrie()
+			n.children[segment] = child
+			n.order = append(n.order, segment)
+		}
+
+		n = child
+		n.entries = append(n.entries, pos)
+	}
+}
+
+// Remove drops the entries at the given positions (in ascending order),
+// which all belong to the key, and shifts the positions of the entries
+// after them (as the entries themselves shift when removed). Only the
+// key's path through the trie changes shape.
+func (x *index) remove(key string, removed []int) {
+	if len(removed) == 0 {
+		return
+	}
+
+	// Key
+	if kept := without(x.keys[key], removed); len(kept) > 0 {
+		x.keys[key] = kept
+	} else {
+		delete(x.keys, key)
+	}
+
+	// Path (Deepest First, Pruning Empty Segments)
+	segments := strings.Split(key, ".")
+	path := []*trie{x.root}
+	for _, segment := range segments {
+		path = append(path, path[len(path)-1].children[segment])
+	}
+
+	for i := len(path) - 1; i >= 0; i-- {
+		n := path[i]
+		first := n.entries[0]
+		n.entries = without(n.entries, removed)
+		if i == 0 {
+			break
+		}
+
+		parent, segment := path[i-1], segments[i-1]
+		switch {
+		case len(n.entries) == 0:
+			delete(parent.children, segment)
+			parent.order = drop(parent.order, segment)
+		case n.entries[0] != first:
+			parent.reorder(segment)
+		}
+	}
+
+	// Shift Later Positions
+	for _, positions := range x.keys {
+		shift(positions, removed)
+	}
+	x.root.shift(removed)
+}
+
+// Moves the child segment to its place in the order (by first appearance)
+func (t *trie) reorder(segment string) {
+	t.order = drop(t.order, segment)
+	first := t.children[segment].entries[0]
+
+	i := sort.Search(len(t.order), func(i int) bool {
+		return t.children[t.order[i]].entries[0] > first
+	})
+	t.order = append(t.order, "")
+	copy(t.order[i+1:], t.order[i:])
+	t.order[i] = segment
+}
+
+// Shifts the positions of the node and its descendants
+func (t *trie) shift(removed []int) {
+	shift(t.entries, removed)
+	for _, child := range t.children {
+		child.shift(removed)
+	}
+}
+
+// Returns the positions (ascending) without the removed ones (ascending)
+func without(positions, removed []int) []int {
+	kept := positions[:0]
+	r := 0
+	for _, pos := range positions {
+		for r < len(removed) && removed[r] < pos {
+			r++
+		}
+		if r < len(removed) && removed[r] == pos {
+			continue
+		}
+		kept = append(kept, pos)
+	}
+	return kept
+}
+
+// Lowers each position by the number of removed positions before it
+func shift(positions, removed []int) {
+	for i, pos := range positions {
+		positions[i] = pos - sort.SearchInts(removed, pos)
+	}
+}
+
+// Returns the list without the string
+func drop(list []string, s string) []string {
+	for i, t := range list {
+		if t == s {
+			return append(list[:i], list[i+1:]...)
+		}
+	}
+	return list
+}
+
+// Returns the positions of the entries for the given key
+func (c *Config) positions(joined string) []int {
+	if c.index == nil {
+		return nil
+	}
+	return c.index.keys[joined]
+}
+
+// Returns the trie node for the given prefix (nil if there is none)
+func (c *Config) lookup(prefix string) *trie {
+	if c.index == nil {
+		return nil
+	}
+
+	n := c.index.root
+	for _, segment := range strings.Split(prefix, ".") {
+		n = n.children[segment]
+		if n == nil {
+			return nil
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,229 @@
+package cfg
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Linear scans (the behavior the index must reproduce)
+func scanSize(c *Config, key string) int {
+	n := 0
+	for _, e := range c.entries {
+		if e.key == key {
+			n++
+		}
+	}
+	return n
+}
+
+func scanSubKeys(c *Config, stem string) []string {
+	result := []string{}
+	seen := make(map[string]bool)
+	for _, e := range c.entries {
+		if !strings.HasPrefix(e.key, stem+".") {
+			continue
+		}
+		sub := strings.Split(strings.TrimPrefix(e.key, stem+"."), ".")[0]
+		if !seen[sub] {
+			seen[sub] = true
+			result = append(result, sub)
+		}
+	}
+	return result
+}
+
+// Check the index against linear scans
+func checkIndex(assert *assert.Assertions, c *Config, keys, stems []string) {
+	for _, key := range keys {
+		assert.Equal(scanSize(c, key), c.Size(key), key)
+		assert.Equal(scanSize(c, key) > 0, c.Has(key), key)
+	}
+
+	for _, stem := range stems {
+		assert.Equal(scanSubKeys(c, stem), c.SubKeys(stem), stem)
+		assert.Equal(len(scanSubKeys(c, stem)) > 0, c.HasPrefix(stem), stem)
+
+		d := c.Descend(stem)
+		for _, sub := range scanSubKeys(c, stem) {
+			assert.Equal(scanSubKeys(c, stem+"."+sub), d.SubKeys(sub), stem+"."+sub)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	assert := assert.New(t)
+	keys := []string{"name", "email", "db.host", "db.port", "db.main.user", "db.main.pass", "db.replica.user", "color", "dbx"}
+	stems := []string{"db", "db.main", "db.replica", "name", "dbx", "missing"}
+
+	c := NewEmpty()
+	checkIndex(assert, c, keys, stems)
+
+	// Add
+	c.Add("name", "bruce")
+	c.Add("email", "a@firm.com")
+	c.Add("db.host", "10.1.1.1")
+	c.Add("db.main.user", "bruce")
+	c.Add("email", "b@firm.com")
+	c.Add("db.replica.user", "leroy")
+	c.Add("dbx", "x")
+	checkIndex(assert, c, keys, stems)
+	assert.Equal([]string{"host", "main", "replica"}, c.SubKeys("db"))
+	assert.Equal("b@firm.com", c.GetN(1, "email"))
+
+	// Set (Drops Later Occurrences, Shifts Positions)
+	c.Set("email", "c@firm.com")
+	c.Set("db.port", "1234")
+	checkIndex(assert, c, keys, stems)
+	assert.Equal("c@firm.com", c.Get("email"))
+	assert.Equal("leroy", c.Get("db.replica.user"))
+
+	// Delete
+	c.Delete("db.host")
+	c.Delete("name")
+	checkIndex(assert, c, keys, stems)
+	assert.Equal([]string{"main", "replica", "port"}, c.SubKeys("db"))
+	assert.Equal("1234", c.Get("db.port"))
+
+	// Descended Configs Are Indexed (And Can Be Modified)
+	d := c.Descend("db")
+	assert.True(d.Has("main.user"))
+	assert.Equal([]string{"user"}, d.SubKeys("main"))
+	d.Add("main.pass", "secret")
+	assert.Equal([]string{"user", "pass"}, d.SubKeys("main"))
+	assert.False(c.Has("db.main.pass"))
+
+	// Loaded Configs
+	l, err := Load("test/test.cfg")
+	assert.Nil(err)
+	var loadedKeys, loadedStems []string
+	for _, e := range l.entries {
+		loadedKeys = append(loadedKeys, e.key)
+		if i := strings.LastIndex(e.key, "."); i > 0 {
+			loadedStems = append(loadedStems, e.key[:i])
+		}
+	}
+	checkIndex(assert, l, loadedKeys, loadedStems)
+}
+
+// Check the index against one rebuilt from scratch
+func checkTrie(assert *assert.Assertions, want, got *trie, path string) {
+	assert.Equal(fmt.Sprint(want.order), fmt.Sprint(got.order), path)
+	assert.Equal(fmt.Sprint(want.entries), fmt.Sprint(got.entries), path)
+	assert.Equal(len(want.children), len(got.children), path)
+
+	for _, segment := range want.order {
+		if child := got.children[segment]; assert.NotNil(child, path+"."+segment) {
+			checkTrie(assert, want.children[segment], child, path+"."+segment)
+		}
+	}
+}
+
+func TestIndexUpdates(t *testing.T) {
+	assert := assert.New(t)
+	keys := []string{"a", "a.x", "a.y", "a.x.deep", "b", "b.z", "c"}
+	random := rand.New(rand.NewSource(7))
+
+	c := NewEmpty()
+	for step := 0; step < 2000; step++ {
+		key := keys[random.Intn(len(keys))]
+
+		switch random.Intn(4) {
+		case 0, 1:
+			c.Add(key, fmt.Sprint(step))
+		case 2:
+			c.Set(key, fmt.Sprint(step))
+		case 3:
+			c.Delete(key)
+		}
+
+		rebuilt := &Config{entries: c.entries}
+		rebuilt.reindex()
+		assert.Equal(len(rebuilt.index.keys), len(c.index.keys))
+		for key, positions := range rebuilt.index.keys {
+			assert.Equal(positions, c.index.keys[key], key)
+		}
+		checkTrie(assert, rebuilt.index.root, c.index.root, "")
+	}
+
+	// First Appearance Moves When The First Occurrence Is Deleted
+	c = NewEmpty()
+	c.Add("db.host", "1")
+	c.Add("db.port", "2")
+	c.Add("db.host.extra", "3")
+	c.Delete("db.host")
+	assert.Equal([]string{"port", "host"}, c.SubKeys("db"))
+}
+
+// Builds a config with tens of thousands of entries:
+// service.<s>.<field> (with a repeated tag per service)
+func largeConfig(services int) *Config {
+	c := NewEmpty()
+	for s := 0; s < services; s++ {
+		stem := fmt.Sprintf("service.s%d", s)
+		c.Add(stem+".host", fmt.Sprintf("10.1.%d.%d", s/256, s%256))
+		c.Add(stem+".port", fmt.Sprintf("%d", 8000+s))
+		c.Add(stem+".user", "bruce")
+		c.Add(stem+".tag", "blue")
+		c.Add(stem+".tag", "green")
+	}
+	return c
+}
+
+func BenchmarkAdd(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		largeConfig(10000)
+	}
+}
+
+func BenchmarkSet(b *testing.B) {
+	c := largeConfig(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Set("service.s5000.host", "10.2.2.2")
+		c.Set(fmt.Sprintf("extra.e%d", i), "x")
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	c := largeConfig(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Get("service.s5000.host")
+	}
+}
+
+func BenchmarkHas(b *testing.B) {
+	c := largeConfig(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Has("service.s5000.missing")
+	}
+}
+
+func BenchmarkSize(b *testing.B) {
+	c := largeConfig(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Size("service.s9999.tag")
+	}
+}
+
+func BenchmarkSubKeys(b *testing.B) {
+	c := largeConfig(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.SubKeys("service.s5000")
+	}
+}
+
+func BenchmarkDescend(b *testing.B) {
+	c := largeConfig(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Descend("service.s5000")
+	}
+}
@@ -0,0 +1,125 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/enova/tokyo/src/args"
+)
+
+// Layer identifies where a value came from. Values from a higher layer
+// override values from a lower layer, regardless of the order in which
+// the layers are applied:
+//
+// LayerFile < LayerEnv < LayerArgs
+type Layer int
+
+// Layers
+const (
+	LayerFile Layer = 0 // Config files (or values set in code)
+	LayerEnv  Layer = 1 // Environment variables (see ApplyEnv)
+	LayerArgs Layer = 2 // Command-line options (see ApplyArgs)
+)
+
+// ArgsPrefix is the prefix of command-line options that override config values
+const ArgsPrefix = "cfg."
+
+// EnvSeparator separates key segments within environment-variable names
+const EnvSeparator = "__"
+
+// Layer-Text
+var layerText = map[Layer]string{
+	LayerFile: "file",
+	LayerEnv:  "env",
+	LayerArgs: "args",
+}
+
+func (l Layer) String() string {
+	s, ok := layerText[l]
+	if ok {
+		return s
+	}
+	return fmt.Sprintf("Layer-(%d)", int(l))
+}
+
+// ApplyEnv overrides config values using environment variables whose
+// names start with the supplied prefix. The remainder of the name is
+// the key with its segments separated by a double-underscore:
+//
+// TOKYO_Alert__Sentry__Use=true => Alert.Sentry.Use true  (prefix "TOKYO_")
+//
+// It returns an error (after applying the other variables) if a variable
+// can't be used, i.e. if its name doesn't make a valid key.
+func (c *Config) ApplyEnv(prefix string) error {
+	var first error
+
+	for _, kv := range os.Environ() {
+		pair := strings.SplitN(kv, "=", 2)
+		if len(pair) != 2 || !strings.HasPrefix(pair[0], prefix) {
+			continue
+		}
+
+		name := strings.TrimPrefix(pair[0], prefix)
+		key := strings.Replace(name, EnvSeparator, ".", -1)
+		if err := c.override(key, pair[1], LayerEnv, "env:"+pair[0]); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// ApplyArgs overrides config values using binary command-line options
+// whose keys start with ArgsPrefix. If an option occurs more than once,
+// the first occurrence is used (as with args.GetOpt):
+//
+// -cfg.Alert.Sentry.Use=true => Alert.Sentry.Use true
+//
+// It returns an error (after applying the other options) if an option
+// can't be used, as ApplyEnv does. Commands using an args.Spec must allow
+// these options (see args.Spec.Passthrough).
+func (c *Config) ApplyArgs(a *args.Args) error {
+	var first error
+
+	for _, opt := range a.OptKeys() {
+		if !strings.HasPrefix(opt, ArgsPrefix) {
+			continue
+		}
+
+		key := strings.TrimPrefix(opt, ArgsPrefix)
+		if err := c.override(key, a.GetOpt(opt), LayerArgs, "args:-"+opt); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// Layer returns the layer the value for the given key came from. It
+// exits(1) under the same conditions as Get().
+func (c *Config) Layer(key ...string) Layer {
+	return c.get(key...).layer
+}
+
+// Override sets the value of a key (as in Set) unless the current value
+// comes from a higher layer. The origin describes the override's source.
+func (c *Config) override(key, val string, layer Layer, origin string) error {
+	for _, e := range c.find(key) {
+		if e.layer > layer {
+			return nil
+		}
+	}
+
+	if err := c.validKey(key); err != nil {
+		return errors.New(err.Error() + " (from " + origin + ")")
+	}
+
+	c.Set(key, val)
+	for _, pos := range c.positions(key) {
+		c.entries[pos].file = origin
+		c.entries[pos].layer = layer
+	}
+	return nil
+}
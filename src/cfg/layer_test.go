@@ -0,0 +1,72 @@
+package cfg
+
+import (
+	"os"
+	"testing"
+
+	"github.com/enova/tokyo/src/args"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayers(t *testing.T) {
+	assert := assert.New(t)
+
+	c := New("test/typed.cfg")
+	assert.Equal(LayerFile, c.Layer("threads"))
+
+	// Environment Overrides File
+	assert.Nil(os.Setenv("CFGTEST_threads", "16"))
+	assert.Nil(os.Setenv("CFGTEST_bad__int", "12"))
+	assert.Nil(os.Setenv("CFGTEST_Alert__Sentry__Use", "true"))
+	defer os.Unsetenv("CFGTEST_threads")
+	defer os.Unsetenv("CFGTEST_bad__int")
+	defer os.Unsetenv("CFGTEST_Alert__Sentry__Use")
+
+	// Command-Line Overrides Environment (Even When Applied First)
+	spec := args.NewSpec("app")
+	spec.Add(args.Opt{Name: "other", Type: args.TypeInt})
+	spec.Passthrough = []string{ArgsPrefix}
+	a, err := spec.Load([]string{"app", "-cfg.threads=32", "-cfg.dup=3", "-other=1", "-cfg.ratio=0.5", "-cfg.ratio=0.6"})
+	assert.Nil(err)
+	assert.Nil(c.ApplyArgs(a))
+	assert.Nil(c.ApplyEnv("CFGTEST_"))
+
+	assert.Equal(32, c.GetI("threads"))
+	assert.Equal(LayerArgs, c.Layer("threads"))
+
+	assert.Equal(12, c.GetI("bad.int"))
+	assert.Equal(LayerEnv, c.Layer("bad", "int"))
+
+	assert.True(c.GetB("Alert", "Sentry", "Use"))
+	assert.Equal(LayerEnv, c.Layer("Alert.Sentry.Use"))
+	assert.True(c.Descend("Alert").GetB("Sentry.Use"))
+
+	// Overrides Replace Repeated Keys
+	assert.Equal("3", c.Get("dup"))
+	assert.Equal(0.5, c.GetF64("ratio"))
+	assert.False(c.Has("other"))
+
+	// Untouched
+	assert.Equal(LayerFile, c.Layer("verbose"))
+
+	// Origins
+	file, line := c.Origin("threads")
+	assert.Equal("args:-cfg.threads", file)
+	assert.Equal(0, line)
+
+	file, _ = c.Origin("bad.int")
+	assert.Equal("env:CFGTEST_bad__int", file)
+
+	// Unusable Overrides Are Reported (Not Fatal)
+	assert.Nil(os.Setenv("CFGTEST_#bad", "1"))
+	defer os.Unsetenv("CFGTEST_#bad")
+	err = c.ApplyEnv("CFGTEST_")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "(from env:CFGTEST_#bad)")
+	assert.Equal(32, c.GetI("threads"))
+
+	// Layer Names
+	assert.Equal("file", LayerFile.String())
+	assert.Equal("env", LayerEnv.String())
+	assert.Equal("args", LayerArgs.String())
+}
@@ -0,0 +1,123 @@
+package cfg
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Heredoc Opening: <<END (The Value Is The Following Lines Up To END)
+var heredocPattern = regexp.MustCompile(`^<<([A-Za-z_][A-Za-z0-9_]*)$`)
+
+// Heredoc is a multi-line value being read
+type heredoc struct {
+	key     string
+	end     string
+	lines   []string
+	lineNum int
+	line    string
+	at      string
+	skip    bool // Inside an inactive conditional block
+}
+
+// Returns the text following the key (with surrounding whitespace removed)
+func valueText(line, key string) string {
+	rest := strings.TrimSpace(line)
+	return strings.TrimSpace(strings.TrimPrefix(rest, key))
+}
+
+// Returns the terminator if the value text opens a heredoc
+func heredocEnd(text string) (string, bool) {
+	m := heredocPattern.FindStringSubmatch(stripComment(text))
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// Parses the value text of a line. A double-quoted value is unquoted (using
+// Go's escape sequences) and kept exactly. Otherwise whitespace is collapsed
+// to a single space. In both cases an inline comment may follow the value.
+// Text that only looks quoted (e.g. "Big" thing, an unterminated quote or
+// an invalid escape) and text that is only a comment are plain values, as
+// they were before quoting and inline comments were supported.
+func parseValue(text string) string {
+	if val, ok := unquoteValue(text); ok {
+		return val
+	}
+
+	val := stripComment(text)
+	if val == "" {
+		val = text
+	}
+	return strings.Join(strings.Fields(val), " ")
+}
+
+// Returns the unquoted value if the text is a double-quoted value,
+// optionally followed by an inline comment
+func unquoteValue(text string) (string, bool) {
+	if !strings.HasPrefix(text, `"`) {
+		return "", false
+	}
+
+	// Find Closing Quote (Skipping Escaped Characters)
+	end := -1
+	for i := 1; i < len(text); i++ {
+		if text[i] == '\\' {
+			i++
+			continue
+		}
+		if text[i] == '"' {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return "", false
+	}
+
+	// Only An Inline Comment May Follow
+	if stripComment(text[end+1:]) != "" {
+		return "", false
+	}
+
+	val, err := strconv.Unquote(text[:end+1])
+	if err != nil {
+		return "", false
+	}
+	return val, true
+}
+
+// Removes an inline comment: a hash preceded by whitespace (or at the start)
+// and followed by whitespace (or at the end). Hashes elsewhere (e.g. #ff0000
+// or a#b) are part of the value. Returns the trimmed remainder.
+func stripComment(text string) string {
+	for i := 0; i < len(text); i++ {
+		if text[i] != '#' {
+			continue
+		}
+
+		before := i == 0 || text[i-1] == ' ' || text[i-1] == '\t'
+		after := i == len(text)-1 || text[i+1] == ' ' || text[i+1] == '\t'
+		if before && after {
+			return strings.TrimSpace(text[:i])
+		}
+	}
+
+	return strings.TrimSpace(text)
+}
+
+// Returns the value as it must be written to a config file: unchanged if
+// it reads back as is, else double-quoted
+func quoteValue(val string) string {
+	plain := val != "" &&
+		strings.Join(strings.Fields(val), " ") == val &&
+		!strings.HasPrefix(val, `"`) &&
+		stripComment(val) == val &&
+		!heredocPattern.MatchString(val)
+
+	if plain {
+		return val
+	}
+	return strconv.Quote(val)
+}
@@ -0,0 +1,265 @@
+package cfg
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type is the expected type of a value
+type Type string
+
+// Types
+const (
+	TypeString   Type = "string"
+	TypeInt      Type = "int"
+	TypeFloat    Type = "float"
+	TypeBool     Type = "bool"
+	TypeDuration Type = "duration"
+	TypeList     Type = "list"
+)
+
+// Rule describes an expected key. Within the key, the segment "*" matches
+// any single segment and a final segment "**" matches one or more segments
+// (e.g. "db.*.host" or "Alert.Sentry.**").
+type Rule struct {
+	Key      string
+	Type     Type     // Defaults to TypeString
+	Required bool     // The key must occur (for wildcards, at least one key must match)
+	Repeated bool     // The key may occur more than once
+	Enum     []string // If non-empty, the value must be one of these
+	Default  string   // Used by ApplyDefaults when the key is missing
+}
+
+// Schema is an ordered list of rules. A key is checked against the first
+// rule with an identical key, else the first wildcard rule that matches.
+type Schema struct {
+	rules []Rule
+}
+
+// Problem describes a single validation failure
+type Problem struct {
+	Key    string
+	Origin string // Location of the offending entry (empty for missing keys)
+	Text   string
+}
+
+// Error formats the problem as: origin: key: text
+func (p Problem) Error() string {
+	if p.Origin == "" {
+		return p.Key + ": " + p.Text
+	}
+	return p.Origin + ": " + p.Key + ": " + p.Text
+}
+
+// NewSchema returns a schema containing the supplied rules
+func NewSchema(rules ...Rule) *Schema {
+	s := &Schema{}
+	for _, r := range rules {
+		s.Add(r)
+	}
+	return s
+}
+
+// Add adds a rule to the schema
+func (s *Schema) Add(r Rule) *Schema {
+	if r.Type == "" {
+		r.Type = TypeString
+	}
+	s.rules = append(s.rules, r)
+	return s
+}
+
+// LoadSchema reads a schema from a file. The file uses the config format
+// (so #INCLUDE, #DEFINE etc. are available). Each key is a rule-key and
+// the value is its type followed by optional attributes:
+//
+// Alert.Sentry.Use   bool default=false
+// Alert.Sentry.DSN   string required
+// Alert.Sentry.Tag   string repeated
+// LogLevel           string enum=Least|Some|Most
+// db.*.port          int
+func LoadSchema(filename string) (*Schema, error) {
+	c, err := Load(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewSchema()
+	for _, e := range c.entries {
+		r, err := parseRule(e.key, e.val)
+		if err != nil {
+			return nil, errors.New("Bad Schema - " + err.Error() + " (at " + e.origin() + ")")
+		}
+		s.Add(r)
+	}
+
+	return s, nil
+}
+
+// Validate checks the config against the schema and returns all problems
+// (in the order of the entries). A valid config returns no problems.
+func (c *Config) Validate(s *Schema) []Problem {
+	var result []Problem
+	counts := make(map[string]int)
+	matched := make([]bool, len(s.rules))
+
+	for _, e := range c.entries {
+		counts[e.key]++
+
+		// Unknown Key
+		i := s.match(e.key)
+		if i < 0 {
+			result = append(result, Problem{e.key, e.origin(), "unknown key"})
+			continue
+		}
+		r := s.rules[i]
+		matched[i] = true
+
+		// Duplicate Key
+		if counts[e.key] > 1 && !r.Repeated {
+			result = append(result, Problem{e.key, e.origin(), "duplicate key"})
+		}
+
+		// Type And Enum (Showing Secrets Unresolved)
+		if err := r.check(e.val, shown([]entry{e})[0]); err != nil {
+			result = append(result, Problem{e.key, e.origin(), err.Error()})
+		}
+	}
+
+	// Missing Required Keys
+	for i, r := range s.rules {
+		if r.Required && !matched[i] {
+			result = append(result, Problem{c.stem + r.Key, "", "missing required key"})
+		}
+	}
+
+	return result
+}
+
+// ApplyDefaults adds the default value for every (non-wildcard) rule
+// with a default whose key is missing
+func (c *Config) ApplyDefaults(s *Schema) {
+	for _, r := range s.rules {
+		if r.Default != "" && !isWildcard(r.Key) && !c.Has(r.Key) {
+			c.Add(r.Key, r.Default)
+		}
+	}
+}
+
+// Returns the index of the rule for the given key (-1 if none)
+func (s *Schema) match(key string) int {
+	for i, r := range s.rules {
+		if r.Key == key {
+			return i
+		}
+	}
+
+	for i, r := range s.rules {
+		if isWildcard(r.Key) && matchKey(r.Key, key) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Check the value's type and enum (problems show the value as shown)
+func (r Rule) check(val, shown string) error {
+	var err error
+
+	switch r.Type {
+	case TypeInt:
+		_, err = strconv.Atoi(val)
+	case TypeFloat:
+		_, err = strconv.ParseFloat(val, 64)
+	case TypeBool:
+		_, err = ParseB(val)
+	case TypeDuration:
+		_, err = time.ParseDuration(val)
+	}
+
+	if err != nil {
+		return errors.New("invalid " + string(r.Type) + ": " + shown)
+	}
+
+	if len(r.Enum) > 0 {
+		for _, v := range r.Enum {
+			if v == val {
+				return nil
+			}
+		}
+		return errors.New("invalid value: " + shown + " (must be one of " + strings.Join(r.Enum, ", ") + ")")
+	}
+
+	return nil
+}
+
+// Parses a rule from a schema file
+func parseRule(key, spec string) (Rule, error) {
+	tokens := strings.Fields(spec)
+	if len(tokens) == 0 {
+		return Rule{Key: key}, errors.New("missing type for key " + key)
+	}
+	r := Rule{Key: key, Type: Type(tokens[0])}
+
+	switch r.Type {
+	case TypeString, TypeInt, TypeFloat, TypeBool, TypeDuration, TypeList:
+	default:
+		return r, errors.New("unknown type for key " + key + ": " + tokens[0])
+	}
+
+	for _, t := range tokens[1:] {
+		switch {
+		case t == "required":
+			r.Required = true
+		case t == "repeated":
+			r.Repeated = true
+		case strings.HasPrefix(t, "enum="):
+			r.Enum = strings.Split(strings.TrimPrefix(t, "enum="), "|")
+		case strings.HasPrefix(t, "default="):
+			r.Default = strings.TrimPrefix(t, "default=")
+		default:
+			return r, errors.New("unknown attribute for key " + key + ": " + t)
+		}
+	}
+
+	if r.Default != "" {
+		if err := r.check(r.Default, r.Default); err != nil {
+			return r, errors.New("bad default for key " + key + ": " + err.Error())
+		}
+	}
+
+	return r, nil
+}
+
+// Returns true if the key contains wildcard segments
+func isWildcard(key string) bool {
+	for _, segment := range strings.Split(key, ".") {
+		if segment == "*" || segment == "**" {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the key matches the pattern (see Rule)
+func matchKey(pattern, key string) bool {
+	p := strings.Split(pattern, ".")
+	k := strings.Split(key, ".")
+
+	for i, segment := range p {
+
+		// Final "**" Matches One Or More Segments
+		if segment == "**" && i == len(p)-1 {
+			return len(k) > i
+		}
+
+		if i >= len(k) || (segment != "*" && segment != k[i]) {
+			return false
+		}
+	}
+
+	return len(p) == len(k)
+}
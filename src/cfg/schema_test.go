@@ -0,0 +1,70 @@
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	schema, err := LoadSchema("test/schema/app.schema")
+	assert.Nil(err)
+
+	// Valid
+	c := New("test/schema/app.cfg")
+	assert.Empty(c.Validate(schema))
+
+	// Defaults
+	assert.False(c.Has("timeout"))
+	c.ApplyDefaults(schema)
+	assert.Equal("30s", c.Get("timeout"))
+	assert.Equal("Most", c.Get("LogLevel"))
+
+	// Invalid
+	c = New("test/schema/app_bad.cfg")
+	problems := c.Validate(schema)
+
+	var texts []string
+	for _, p := range problems {
+		texts = append(texts, p.Error())
+	}
+
+	assert.Equal([]string{
+		"test/schema/app_bad.cfg:3: LogLevel: invalid value: Everything (must be one of Least, Some, Most)",
+		"test/schema/app_bad.cfg:4: ratio: invalid float: half",
+		"test/schema/app_bad.cfg:6: timeout: duplicate key",
+		"test/schema/app_bad.cfg:8: Alert.Sentry.Dns: unknown key",
+		"test/schema/app_bad.cfg:10: db.us.port: invalid int: port",
+		"threads: missing required key",
+		"db.*.host: missing required key",
+	}, texts)
+
+	// Descended Configs
+	d := New("test/schema/app_bad.cfg").Descend("Alert")
+	problems = d.Validate(NewSchema(Rule{Key: "Sentry.DSN", Required: true}))
+	assert.Equal(2, len(problems))
+	assert.Equal("Alert.Sentry.DSN: missing required key", problems[1].Error())
+
+	// Bad Schema
+	_, err = LoadSchema("test/schema/bad.schema")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "test/schema/bad.schema:3")
+
+	_, err = LoadSchema("test/schema/empty_type.schema")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "missing type for key threads (at test/schema/empty_type.schema:3)")
+}
+
+func TestMatchKey(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(matchKey("db.*.host", "db.us.host"))
+	assert.False(matchKey("db.*.host", "db.us.east.host"))
+	assert.False(matchKey("db.*.host", "db.host"))
+	assert.True(matchKey("db.**", "db.us"))
+	assert.True(matchKey("db.**", "db.us.east.host"))
+	assert.False(matchKey("db.**", "db"))
+	assert.False(matchKey("db.*", "dbx.us"))
+}
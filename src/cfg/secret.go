@@ -0,0 +1,109 @@
+package cfg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Matches references: ${env:NAME}, ${env:NAME:-default}, ${file:path}
+var refPattern = regexp.MustCompile(`\$\{(env|file):([^}]*)\}`)
+
+// Resolves all references within a value. Relative file references are
+// resolved against dir (the directory of the config file). Returns the
+// resolved value and true if any references were found.
+func resolveRefs(val, dir string) (string, bool, error) {
+	var failure error
+
+	result := refPattern.ReplaceAllStringFunc(val, func(ref string) string {
+		parts := refPattern.FindStringSubmatch(ref)
+		kind, target := parts[1], parts[2]
+
+		switch kind {
+
+		// Environment Variable (With Optional Default)
+		case "env":
+			name, def, hasDef := target, "", false
+			if i := strings.Index(target, ":-"); i >= 0 {
+				name, def, hasDef = target[:i], target[i+2:], true
+			}
+
+			if value := os.Getenv(name); value != "" {
+				return value
+			}
+
+			if !hasDef && failure == nil {
+				failure = errors.New("Bad Reference - Environment variable " + name + " is not set: " + ref)
+			}
+			return def
+
+		// File Contents (Without Trailing Newlines)
+		case "file":
+			path := target
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+
+			body, err := ioutil.ReadFile(path)
+			if err != nil && failure == nil {
+				failure = errors.New("Bad Reference - Can't read file: " + ref + ", " + err.Error())
+			}
+			return strings.TrimRight(string(body), "\r\n")
+		}
+
+		return ref
+	})
+
+	if failure != nil {
+		return "", false, failure
+	}
+
+	return result, refPattern.MatchString(val), nil
+}
+
+// Resolves the references within a value (see resolveRefs), recording
+// the referenced files so that a Watcher notices when they change
+func (c *Config) resolve(val, dir string) (string, bool, error) {
+	for _, parts := range refPattern.FindAllStringSubmatch(val, -1) {
+		if parts[1] != "file" {
+			continue
+		}
+
+		path := parts[2]
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		c.watched.Insert(path)
+	}
+
+	return resolveRefs(val, dir)
+}
+
+// Dump returns the entries as text for debugging, one per line, each
+// followed by its origin. Values resolved from references (e.g. secrets
+// read from files) are shown unresolved and marked as secret.
+func (c *Config) Dump() string {
+	var buffer bytes.Buffer
+
+	pad := 0
+	for _, e := range c.entries {
+		if len(e.key) > pad {
+			pad = len(e.key)
+		}
+	}
+
+	for _, e := range c.entries {
+		val := quoteValue(e.val)
+		if e.secret {
+			val = quoteValue(e.raw) + " (secret)"
+		}
+		fmt.Fprintf(&buffer, "%-*s  %s  # %s\n", pad, e.key, val, e.origin())
+	}
+
+	return buffer.String()
+}
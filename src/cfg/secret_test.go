@@ -0,0 +1,67 @@
+package cfg
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecrets(t *testing.T) {
+	assert := assert.New(t)
+	assert.Nil(os.Setenv("CFG_TEST_USER", "bruce"))
+	defer os.Unsetenv("CFG_TEST_USER")
+
+	c := New("test/secret/secret.cfg")
+
+	// Resolved
+	assert.Equal("bruce", c.Get("db.user"))
+	assert.Equal("s3cr3t", c.Get("db.password"))
+	assert.Equal("localhost", c.Get("db.host"))
+	assert.Equal("postgres://bruce@ localhost", c.Get("db.url"))
+
+	// Dump Masks Resolved Values
+	dump := c.Dump()
+	assert.NotContains(dump, "s3cr3t")
+	assert.NotContains(dump, "bruce")
+	assert.Contains(dump, "db.password  ${file:db_password} (secret)  # test/secret/secret.cfg:4")
+	assert.Contains(dump, "db.url       postgres://${env:CFG_TEST_USER}@ ${env:CFG_TEST_UNSET_HOST:-localhost} (secret)")
+	assert.Contains(dump, "db.name      inventory  # test/secret/secret.cfg:8")
+
+	// Validation Problems Mask Resolved Values
+	problems := c.Validate(NewSchema(Rule{Key: "db.*", Type: TypeInt}))
+	assert.Equal(5, len(problems))
+	assert.Equal("test/secret/secret.cfg:4: db.password: invalid int: ${file:db_password}", problems[1].Error())
+	for _, p := range problems {
+		assert.NotContains(p.Error(), "s3cr3t")
+		assert.NotContains(p.Error(), "bruce")
+	}
+
+	// Writing Keeps References
+	var buffer bytes.Buffer
+	_, err := c.WriteTo(&buffer)
+	assert.Nil(err)
+	assert.NotContains(buffer.String(), "s3cr3t")
+	assert.Contains(buffer.String(), "${file:db_password}")
+
+	// Tree Keeps Both
+	password := c.Tree().Child("db").Child("password")
+	assert.Equal([]string{"s3cr3t"}, password.Values)
+	assert.Equal([]string{"${file:db_password}"}, password.Raw)
+	assert.Equal([]string{"inventory"}, c.Tree().Child("db").Child("name").Raw)
+
+	// Set Replaces The Reference
+	c.Set("db.password", "plain")
+	assert.Contains(c.Dump(), "db.password  plain  #")
+
+	// Bad References
+	_, err = Load("test/bad/missing_ref.cfg")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "CFG_TEST_UNSET_PASSWORD")
+	assert.Contains(err.Error(), "test/bad/missing_ref.cfg:3")
+
+	_, err = Load("test/bad/missing_ref_file.cfg")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "test/bad/missing_ref_file.cfg:3")
+}
@@ -0,0 +1,86 @@
+package cfg
+
+// Node is a single segment within the key hierarchy of a config (see Tree).
+// A node has values if its key occurs in the config and children if its key
+// is a prefix of other keys. A node can have both:
+//
+// db       10.1.1.1
+// db.port  1234
+type Node struct {
+	Name     string   // Segment (empty for the root)
+	Key      string   // Full key (empty for the root)
+	Values   []string // Values of the key, in order (more than one if repeated)
+	Raw      []string // The values with references (e.g. ${file:...}) left unresolved
+	Children []*Node  // In order of first appearance
+}
+
+// Tree returns the key hierarchy of the config. The root node has no name
+// and its children are the first segments of the keys:
+//
+// db.us.user  bruce
+// db.us.host  10.1.1.1
+// email       a@firm.com
+// email       b@firm.com
+//
+// (root)
+// ├── db
+// │   └── us
+// │       ├── user  [bruce]
+// │       └── host  [10.1.1.1]
+// └── email         [a@firm.com b@firm.com]
+func (c *Config) Tree() *Node {
+	root := &Node{}
+	if c.index != nil {
+		c.grow(root, c.index.root, "")
+	}
+	return root
+}
+
+// Adds the children of the trie-node to the tree-node. The prefix is the
+// tree-node's key followed by a dot (empty for the root).
+func (c *Config) grow(n *Node, t *trie, prefix string) {
+	for _, name := range t.order {
+		child := &Node{Name: name, Key: prefix + name}
+
+		for _, pos := range c.positions(child.Key) {
+			e := c.entries[pos]
+			child.Values = append(child.Values, e.val)
+			child.Raw = append(child.Raw, shown([]entry{e})[0])
+		}
+
+		c.grow(child, t.children[name], child.Key+".")
+		n.Children = append(n.Children, child)
+	}
+}
+
+// Child returns the child with the given name (nil if there is none)
+func (n *Node) Child(name string) *Node {
+	for _, child := range n.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// IsLeaf returns true if the node has no children
+func (n *Node) IsLeaf() bool {
+	return len(n.Children) == 0
+}
+
+// Walk calls fn for the node and its descendants (depth-first, in order).
+// The depth of the node passed to Walk is zero. If fn returns false, the
+// node's children are skipped.
+func (n *Node) Walk(fn func(n *Node, depth int) bool) {
+	n.walk(fn, 0)
+}
+
+func (n *Node) walk(fn func(n *Node, depth int) bool, depth int) {
+	if !fn(n, depth) {
+		return
+	}
+
+	for _, child := range n.Children {
+		child.walk(fn, depth+1)
+	}
+}
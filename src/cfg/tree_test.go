@@ -0,0 +1,53 @@
+package cfg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTree(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewEmpty()
+	c.Add("db", "10.1.1.1")
+	c.Add("db.us.user", "bruce")
+	c.Add("db.us.host", "10.1.1.2")
+	c.Add("email", "a@firm.com")
+	c.Add("db.uk.user", "leroy")
+	c.Add("email", "b@firm.com")
+
+	root := c.Tree()
+	assert.Equal("", root.Key)
+	assert.Nil(root.Values)
+	assert.Equal(2, len(root.Children))
+
+	// Values And Children
+	db := root.Child("db")
+	assert.Equal([]string{"10.1.1.1"}, db.Values)
+	assert.False(db.IsLeaf())
+	assert.Equal("us", db.Children[0].Name)
+	assert.Equal("uk", db.Children[1].Name)
+
+	user := db.Child("us").Child("user")
+	assert.Equal("db.us.user", user.Key)
+	assert.Equal([]string{"bruce"}, user.Values)
+	assert.True(user.IsLeaf())
+	assert.Nil(db.Child("u"))
+
+	// Repeated Values
+	assert.Equal([]string{"a@firm.com", "b@firm.com"}, root.Child("email").Values)
+
+	// Walk (Skipping The Children Of db.us)
+	var lines []string
+	root.Walk(func(n *Node, depth int) bool {
+		lines = append(lines, strings.Repeat(" ", depth)+n.Name+" "+strings.Join(n.Values, ","))
+		return n.Key != "db.us"
+	})
+	assert.Equal([]string{" ", " db 10.1.1.1", "  us ", "  uk ", "   user leroy", " email a@firm.com,b@firm.com"}, lines)
+
+	// Sub-Configs
+	assert.Equal([]string{"bruce"}, c.Descend("db").Tree().Child("us").Child("user").Values)
+	assert.True(NewEmpty().Tree().IsLeaf())
+}
@@ -0,0 +1,128 @@
+package cfg
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enova/tokyo/src/args"
+)
+
+// ParseB converts a string into a boolean (see args.ParseB for the
+// accepted values)
+func ParseB(s string) (bool, error) {
+	return args.ParseB(s)
+}
+
+// GetI returns the value for the given key as an integer. It exits(1)
+// under the same conditions as Get() and also if the value is not an integer.
+func (c *Config) GetI(key ...string) int {
+	return c.toI(c.get(key...))
+}
+
+// GetF64 returns the value for the given key as a float64. It exits(1)
+// under the same conditions as Get() and also if the value is not a number.
+func (c *Config) GetF64(key ...string) float64 {
+	return c.toF64(c.get(key...))
+}
+
+// GetB returns the value for the given key as a boolean (see ParseB). It exits(1)
+// under the same conditions as Get() and also if the value is not a boolean.
+func (c *Config) GetB(key ...string) bool {
+	return c.toB(c.get(key...))
+}
+
+// GetDuration returns the value for the given key as a time.Duration (e.g. "1h30m").
+// It exits(1) under the same conditions as Get() and also if the value is not a duration.
+func (c *Config) GetDuration(key ...string) time.Duration {
+	return c.toDuration(c.get(key...))
+}
+
+// GetList returns the value for the given key split on whitespace.
+// It exits(1) under the same conditions as Get().
+func (c *Config) GetList(key ...string) []string {
+	return strings.Fields(c.Get(key...))
+}
+
+// GetOr returns the value for the given key. If the key does not
+// exist it returns the supplied default. If there are multiple
+// occurrences of the key it exits(1).
+func (c *Config) GetOr(def string, key ...string) string {
+	if !c.Has(key...) {
+		return def
+	}
+	return c.Get(key...)
+}
+
+// GetIOr is the integer version of GetOr()
+func (c *Config) GetIOr(def int, key ...string) int {
+	if !c.Has(key...) {
+		return def
+	}
+	return c.GetI(key...)
+}
+
+// GetF64Or is the float64 version of GetOr()
+func (c *Config) GetF64Or(def float64, key ...string) float64 {
+	if !c.Has(key...) {
+		return def
+	}
+	return c.GetF64(key...)
+}
+
+// GetBOr is the boolean version of GetOr()
+func (c *Config) GetBOr(def bool, key ...string) bool {
+	if !c.Has(key...) {
+		return def
+	}
+	return c.GetB(key...)
+}
+
+// GetDurationOr is the time.Duration version of GetOr()
+func (c *Config) GetDurationOr(def time.Duration, key ...string) time.Duration {
+	if !c.Has(key...) {
+		return def
+	}
+	return c.GetDuration(key...)
+}
+
+// GetListOr is the list version of GetOr()
+func (c *Config) GetListOr(def []string, key ...string) []string {
+	if !c.Has(key...) {
+		return def
+	}
+	return c.GetList(key...)
+}
+
+// Conversions (Exit On Failure, Showing Secrets Unresolved)
+func (c *Config) toI(e entry) int {
+	i, err := strconv.Atoi(e.val)
+	if err != nil {
+		exit("Config - Invalid integer for key " + e.key + ": " + shown([]entry{e})[0] + c.suffix() + " (at " + e.origin() + ")")
+	}
+	return i
+}
+
+func (c *Config) toF64(e entry) float64 {
+	f, err := strconv.ParseFloat(e.val, 64)
+	if err != nil {
+		exit("Config - Invalid float for key " + e.key + ": " + shown([]entry{e})[0] + c.suffix() + " (at " + e.origin() + ")")
+	}
+	return f
+}
+
+func (c *Config) toB(e entry) bool {
+	b, err := ParseB(e.val)
+	if err != nil {
+		exit("Config - Invalid boolean for key " + e.key + ": " + shown([]entry{e})[0] + c.suffix() + " (at " + e.origin() + ")")
+	}
+	return b
+}
+
+func (c *Config) toDuration(e entry) time.Duration {
+	d, err := time.ParseDuration(e.val)
+	if err != nil {
+		exit("Config - Invalid duration for key " + e.key + ": " + shown([]entry{e})[0] + c.suffix() + " (at " + e.origin() + ")")
+	}
+	return d
+}
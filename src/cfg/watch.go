@@ -0,0 +1,196 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WatchInterval is the default polling interval used by Watch
+const WatchInterval = time.Second
+
+// Changes lists the keys that differ between two configs. A key is
+// modified if its value (or any of its values for repeated keys) differs.
+type Changes struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
+
+// Empty returns true if there are no changes
+func (c *Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// WatchFunc is invoked by a Watcher after the watched files change.
+// On success, cfg is the newly parsed config and changes lists the
+// differences from the previous one. If the files could not be parsed,
+// err is non-nil, cfg is the previous config and changes is nil.
+type WatchFunc func(cfg *Config, changes *Changes, err error)
+
+// Watcher polls a config file (and all of its included files) for changes.
+// The patterns of #INCLUDE directives are expanded on each poll, so files
+// created later (e.g. a new conf.d/*.cfg file or a missing #INCLUDE?
+// target) are noticed, as are changes to files referenced by ${file:...}.
+type Watcher struct {
+	filename string
+	interval time.Duration
+	callback WatchFunc
+	lock     sync.Mutex // Guards config
+	config   *Config
+	files    []string         // Files and patterns to poll (see track)
+	stamps   map[string]stamp // Only accessed by the polling goroutine
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// Stamp identifies the state of a file on disk
+type stamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// Watch loads the supplied file and starts polling it (and all of its
+// included files) every WatchInterval. The callback is invoked each time
+// the files change. It returns an error if the initial load fails.
+func Watch(filename string, callback WatchFunc) (*Watcher, error) {
+	return WatchEvery(filename, WatchInterval, callback)
+}
+
+// WatchEvery is like Watch but polls using the supplied interval
+func WatchEvery(filename string, interval time.Duration, callback WatchFunc) (*Watcher, error) {
+	c, err := Load(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		filename: filename,
+		interval: interval,
+		callback: callback,
+		config:   c,
+		done:     make(chan struct{}),
+	}
+	w.track(c)
+
+	go w.run()
+	return w, nil
+}
+
+// Config returns the most recently (successfully) parsed config
+func (w *Watcher) Config() *Config {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.config
+}
+
+// Stop stops polling
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+	})
+}
+
+// Poll Until Stopped
+func (w *Watcher) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+// Reparse If Any Watched File Changed
+func (w *Watcher) poll() {
+
+	// Compare Stamps
+	if sameStamps(w.stamps, stampFiles(w.files)) {
+		return
+	}
+
+	// Reparse (Keep Previous Config On Failure, But Watch The Files It Reached)
+	c, err := parse(w.filename)
+	if err != nil {
+		w.track(c)
+		w.callback(w.Config(), nil, err)
+		return
+	}
+
+	// Swap Configs
+	w.lock.Lock()
+	changes := diffEntries(w.config, c)
+	w.config = c
+	w.lock.Unlock()
+
+	// Watch The New Set Of Included Files
+	w.track(c)
+	w.callback(c, changes, nil)
+}
+
+// Polls the files (and patterns) of the supplied config from now on. The
+// root file is always polled, even if it couldn't be opened (e.g. while an
+// editor replaces it), so that its return is noticed.
+func (w *Watcher) track(c *Config) {
+	w.files = append([]string{w.filename}, c.includes.Elements()...)
+	w.files = append(w.files, c.watched.Elements()...)
+	w.stamps = stampFiles(w.files)
+}
+
+// Returns the stamps for the supplied files. Glob patterns are expanded,
+// so a file starting or ceasing to match adds or removes a stamp.
+func stampFiles(files []string) map[string]stamp {
+	result := make(map[string]stamp, len(files))
+
+	for _, f := range files {
+		if !strings.ContainsAny(f, "*?[") {
+			result[f] = stampFile(f)
+			continue
+		}
+
+		matches, _ := filepath.Glob(f)
+		for _, m := range matches {
+			result[m] = stampFile(m)
+		}
+	}
+
+	return result
+}
+
+// Returns the stamp for the supplied file
+func stampFile(f string) stamp {
+	info, err := os.Stat(f)
+	if err != nil {
+		return stamp{}
+	}
+
+	return stamp{
+		exists:  true,
+		size:    info.Size(),
+		modTime: info.ModTime(),
+	}
+}
+
+// Returns true if both sets of stamps are identical
+func sameStamps(a, b map[string]stamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for f, s := range a {
+		t, ok := b[f]
+		if !ok || s.exists != t.exists || s.size != t.size || !s.modTime.Equal(t.modTime) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,184 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Event records a single invocation of a WatchFunc
+type event struct {
+	cfg     *Config
+	changes *Changes
+	err     error
+}
+
+// Write a file and bump its modification time (so that quick successive writes are detected)
+func writeWatched(assert *assert.Assertions, filename, body string, age int) {
+	assert.Nil(ioutil.WriteFile(filename, []byte(body), 0644))
+	stamp := time.Now().Add(time.Duration(age) * time.Second)
+	assert.Nil(os.Chtimes(filename, stamp, stamp))
+}
+
+// Wait for the next event (or fail)
+func nextEvent(t *testing.T, events chan event) event {
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the watcher")
+	}
+	return event{}
+}
+
+func TestWatch(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "cfg_watch")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "root.cfg")
+	incl := filepath.Join(dir, "incl.cfg")
+
+	writeWatched(assert, incl, "color blue\n", 1)
+	writeWatched(assert, root, "#INCLUDE "+incl+"\nname bruce\nsize 1\nsize 2\n", 1)
+
+	// Start Watching
+	events := make(chan event, 10)
+	w, err := WatchEvery(root, 10*time.Millisecond, func(c *Config, changes *Changes, err error) {
+		events <- event{c, changes, err}
+	})
+	assert.Nil(err)
+	defer w.Stop()
+	assert.Equal("bruce", w.Config().Get("name"))
+
+	// Modify Root File
+	writeWatched(assert, root, "#INCLUDE "+incl+"\nname leroy\nsize 1\nsize 3\nage 9\n", 2)
+	e := nextEvent(t, events)
+	assert.Nil(e.err)
+	assert.Equal("leroy", e.cfg.Get("name"))
+	assert.Equal("leroy", w.Config().Get("name"))
+	assert.Equal([]string{"age"}, e.changes.Added)
+	assert.Equal([]string{"name", "size"}, e.changes.Modified)
+	assert.Empty(e.changes.Removed)
+
+	// Modify Included File
+	writeWatched(assert, incl, "shape round\n", 3)
+	e = nextEvent(t, events)
+	assert.Nil(e.err)
+	assert.Equal([]string{"shape"}, e.changes.Added)
+	assert.Equal([]string{"color"}, e.changes.Removed)
+	assert.Empty(e.changes.Modified)
+
+	// Bad Edit (Keeps Previous Config)
+	writeWatched(assert, root, "#DEFINE <oops 1\n", 4)
+	e = nextEvent(t, events)
+	assert.NotNil(e.err)
+	assert.Nil(e.changes)
+	assert.Equal("leroy", e.cfg.Get("name"))
+	assert.Equal("leroy", w.Config().Get("name"))
+
+	// Fixed
+	writeWatched(assert, root, "name bruce\n", 5)
+	e = nextEvent(t, events)
+	assert.Nil(e.err)
+	assert.Equal("bruce", w.Config().Get("name"))
+	assert.Equal([]string{"shape", "size", "age"}, e.changes.Removed)
+	assert.Equal([]string{"name"}, e.changes.Modified)
+}
+
+func TestWatchMissingFile(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := Watch("test/nonexistent.cfg", func(c *Config, changes *Changes, err error) {})
+	assert.NotNil(err)
+}
+
+func TestWatchPatterns(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "cfg_watch")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "root.cfg")
+	confd := filepath.Join(dir, "conf.d")
+	assert.Nil(os.Mkdir(confd, 0755))
+
+	writeWatched(assert, filepath.Join(confd, "a.cfg"), "color blue\n", 1)
+	writeWatched(assert, filepath.Join(dir, "password"), "secret1\n", 1)
+	writeWatched(assert, root, "#INCLUDE conf.d/*.cfg\n#INCLUDE? local.cfg\npassword ${file:password}\n", 1)
+
+	events := make(chan event, 10)
+	w, err := WatchEvery(root, 10*time.Millisecond, func(c *Config, changes *Changes, err error) {
+		events <- event{c, changes, err}
+	})
+	assert.Nil(err)
+	defer w.Stop()
+
+	// New File Matching The Glob
+	writeWatched(assert, filepath.Join(confd, "b.cfg"), "shape round\n", 2)
+	e := nextEvent(t, events)
+	assert.Nil(e.err)
+	assert.Equal([]string{"shape"}, e.changes.Added)
+
+	// Optional Include Created
+	writeWatched(assert, filepath.Join(dir, "local.cfg"), "size 9\n", 3)
+	e = nextEvent(t, events)
+	assert.Nil(e.err)
+	assert.Equal([]string{"size"}, e.changes.Added)
+
+	// Referenced File Changed
+	writeWatched(assert, filepath.Join(dir, "password"), "secret2\n", 4)
+	e = nextEvent(t, events)
+	assert.Nil(e.err)
+	assert.Equal([]string{"password"}, e.changes.Modified)
+	assert.Equal("secret2", w.Config().Get("password"))
+
+	// Include Added By A Failed Parse Is Watched
+	extra := filepath.Join(dir, "extra.cfg")
+	writeWatched(assert, root, "#INCLUDE "+extra+"\n", 5)
+	e = nextEvent(t, events)
+	assert.NotNil(e.err)
+
+	writeWatched(assert, extra, "name bruce\n", 6)
+	e = nextEvent(t, events)
+	assert.Nil(e.err)
+	assert.Equal("bruce", w.Config().Get("name"))
+}
+
+func TestWatchReplacedRoot(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "cfg_watch")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "root.cfg")
+	writeWatched(assert, root, "name bruce\n", 1)
+
+	events := make(chan event, 10)
+	w, err := WatchEvery(root, 10*time.Millisecond, func(c *Config, changes *Changes, err error) {
+		events <- event{c, changes, err}
+	})
+	assert.Nil(err)
+	defer w.Stop()
+
+	// Removed (Keeps Previous Config)
+	assert.Nil(os.Remove(root))
+	e := nextEvent(t, events)
+	assert.NotNil(e.err)
+	assert.Equal("bruce", w.Config().Get("name"))
+
+	// Recreated
+	writeWatched(assert, root, "name leroy\n", 2)
+	e = nextEvent(t, events)
+	assert.Nil(e.err)
+	assert.Equal([]string{"name"}, e.changes.Modified)
+	assert.Equal("leroy", w.Config().Get("name"))
+}
@@ -0,0 +1,233 @@
+package cfg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/enova/tokyo/src/set"
+)
+
+// NewEmpty returns a new Config object with no entries
+func NewEmpty() *Config {
+	c := &Config{
+		defines:  make(map[string]string),
+		includes: set.NewS(),
+	}
+	c.reindex()
+	return c
+}
+
+// Set sets the value for the given key. If the key occurs, the first
+// occurrence keeps its position and any further occurrences are removed.
+// Otherwise the key is added to the end. The value is kept exactly (it is
+// quoted when written if needed, see WriteFolded), so it may be empty or
+// contain any whitespace. It exits(1) if the key can't be represented in a
+// config file.
+func (c *Config) Set(key, val string) {
+	c.checkKey(key)
+	if c.index == nil {
+		c.reindex()
+	}
+
+	// New Key
+	positions := c.positions(key)
+	if len(positions) == 0 {
+		c.entries = append(c.entries, entry{key: key, val: val})
+		c.index.insert(key, len(c.entries)-1)
+		return
+	}
+
+	// Replace First Occurrence, Drop The Rest
+	e := &c.entries[positions[0]]
+	e.val = val
+	e.file, e.line, e.layer = "", 0, LayerFile
+	e.secret, e.raw = false, ""
+
+	c.remove(key, positions[1:])
+}
+
+// Add appends an occurrence of the given key (see Set)
+func (c *Config) Add(key, val string) {
+	c.checkKey(key)
+	c.entries = append(c.entries, entry{key: key, val: val})
+
+	if c.index == nil {
+		c.reindex()
+		return
+	}
+	c.index.insert(key, len(c.entries)-1)
+}
+
+// Delete removes all occurrences of the given key
+func (c *Config) Delete(key string) {
+	if c.index == nil {
+		c.reindex()
+	}
+	c.remove(key, c.positions(key))
+}
+
+// Removes the entries of the key at the given positions (ascending)
+func (c *Config) remove(key string, removed []int) {
+	if len(removed) == 0 {
+		return
+	}
+
+	// Copy The Positions (They May Belong To The Index)
+	removed = append([]int(nil), removed...)
+
+	kept := c.entries[:removed[0]]
+	r := 0
+	for pos := removed[0]; pos < len(c.entries); pos++ {
+		if r < len(removed) && removed[r] == pos {
+			r++
+			continue
+		}
+		kept = append(kept, c.entries[pos])
+	}
+	c.entries = kept
+
+	c.index.remove(key, removed)
+}
+
+// WriteTo writes the config in the native format (see WriteFolded)
+func (c *Config) WriteTo(w io.Writer) (int64, error) {
+	return c.WriteFolded(w, 0)
+}
+
+// WriteFolded writes the config in the native format. Defines and includes
+// have already been applied so the output is a single self-contained file.
+// Comments that preceded an entry in the original file are written before it.
+// Values resolved from references (e.g. ${env:NAME}) are written unresolved.
+// Values that would read back differently (e.g. with repeated spaces, line
+// breaks or a " # ") are written double-quoted.
+// If width is positive, values longer than width are folded onto
+// continuation lines (key+= ...) at word boundaries.
+func (c *Config) WriteFolded(w io.Writer, width int) (int64, error) {
+	out := &countingWriter{w: bufio.NewWriter(w)}
+
+	// Align Values (Leave Room For +=)
+	pad := 0
+	for _, e := range c.entries {
+		if len(e.key) > pad {
+			pad = len(e.key)
+		}
+	}
+	pad += 2
+
+	for i, e := range c.entries {
+
+		// Comments (Separated From The Previous Entry)
+		if len(e.comments) > 0 {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			for _, comment := range e.comments {
+				fmt.Fprintln(out, comment)
+			}
+		}
+
+		// Keep References Unresolved (Don't Write Secrets)
+		val := e.val
+		if e.secret {
+			val = e.raw
+		}
+
+		// Values That Don't Read Back As Is Are Quoted (And Not Folded)
+		segments := fold(val, width)
+		if quoted := quoteValue(val); quoted != val || !plain(segments) {
+			segments = []string{quoted}
+		}
+
+		// Key-Value (With Continuation Lines)
+		for f, segment := range segments {
+			key := e.key
+			if f > 0 {
+				key += "+="
+			}
+			fmt.Fprintf(out, "%-*s %s\n", pad, key, segment)
+		}
+	}
+
+	if out.err != nil {
+		return out.n, out.err
+	}
+	return out.n, out.w.Flush()
+}
+
+// Checks that the key can be written to (and read back from) a config
+// file. It exits(1) if the key is invalid.
+func (c *Config) checkKey(key string) {
+	if err := c.validKey(key); err != nil {
+		exit(err.Error())
+	}
+}
+
+// Like checkKey but returns an error instead of exiting
+func (c *Config) validKey(key string) error {
+	if key == "" || len(strings.Fields(key)) != 1 || key != strings.TrimSpace(key) {
+		return errors.New("Config - Key must be a single non-empty token: '" + key + "'" + c.suffix())
+	}
+
+	if strings.HasPrefix(key, "#") || strings.HasSuffix(key, "+=") {
+		return errors.New("Config - Key can't start with # or end with +=: " + key + c.suffix())
+	}
+
+	return nil
+}
+
+// Splits a value into segments no longer than width (unless a single word
+// is longer). A non-positive width returns the value unchanged.
+func fold(val string, width int) []string {
+	if width <= 0 || len(val) <= width {
+		return []string{val}
+	}
+
+	var result []string
+	var current string
+
+	for _, word := range strings.Fields(val) {
+		if current != "" && len(current)+1+len(word) > width {
+			result = append(result, current)
+			current = ""
+		}
+
+		if current != "" {
+			current += " "
+		}
+		current += word
+	}
+
+	return append(result, current)
+}
+
+// Returns true if every segment reads back as is (e.g. a continuation
+// line can't start with a quote)
+func plain(segments []string) bool {
+	for _, segment := range segments {
+		if quoteValue(segment) != segment {
+			return false
+		}
+	}
+	return true
+}
+
+// CountingWriter counts bytes written and remembers the first error
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
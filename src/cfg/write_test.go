@@ -0,0 +1,104 @@
+package cfg
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Write a config to a temporary file and read it back
+func roundTrip(assert *assert.Assertions, c *Config, width int) (*Config, string) {
+	var buffer bytes.Buffer
+	n, err := c.WriteFolded(&buffer, width)
+	assert.Nil(err)
+	assert.Equal(int64(buffer.Len()), n)
+
+	file, err := ioutil.TempFile("", "cfg_write")
+	assert.Nil(err)
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(buffer.Bytes())
+	assert.Nil(err)
+	file.Close()
+
+	result, err := Load(file.Name())
+	assert.Nil(err)
+	return result, buffer.String()
+}
+
+func TestWrite(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewEmpty()
+	assert.False(c.Has("name"))
+
+	// Set, Add, Delete
+	c.Set("name", "bruce")
+	c.Add("email", "a@firm.com")
+	c.Add("email", "b@firm.com")
+	c.Set("db.host", "10.1.1.1")
+	c.Set("db.port", "1234")
+	c.Add("junk", "x")
+	c.Set("name", "leroy green") // Keeps position
+	c.Delete("junk")
+
+	assert.Equal("leroy green", c.Get("name"))
+	assert.Equal(2, c.Size("email"))
+	assert.False(c.Has("junk"))
+	assert.Equal([]string{"host", "port"}, c.SubKeys("db"))
+
+	// Set Replaces All Occurrences
+	c.Add("color", "red")
+	c.Add("color", "blue")
+	c.Set("color", "green")
+	assert.Equal("green", c.Get("color"))
+
+	// Write And Read Back
+	d, text := roundTrip(assert, c, 0)
+	assert.Equal("name      leroy green\nemail     a@firm.com\nemail     b@firm.com\ndb.host   10.1.1.1\ndb.port   1234\ncolor     green\n", text)
+	assert.True(diffEntries(c, d).Empty())
+
+	// Folding
+	c.Set("slogan", "We love to code all day long")
+	d, text = roundTrip(assert, c, 10)
+	assert.Contains(text, "slogan    We love to\nslogan+=  code all\nslogan+=  day long\n")
+	assert.Equal("We love to code all day long", d.Get("slogan"))
+	assert.True(diffEntries(c, d).Empty())
+
+	// Values Are Kept Exactly (Quoted When Written)
+	c.Set("padded", "  two  spaces ")
+	c.Set("blank", "")
+	d, text = roundTrip(assert, c, 0)
+	assert.Contains(text, "padded    \"  two  spaces \"\nblank     \"\"\n")
+	assert.Equal("  two  spaces ", d.Get("padded"))
+	assert.Equal("", d.Get("blank"))
+	assert.True(diffEntries(c, d).Empty())
+}
+
+func TestWriteParsed(t *testing.T) {
+	assert := assert.New(t)
+	assert.Nil(os.Setenv("CFG_TEST", "all-good"))
+
+	// Defines And Includes Are Expanded, Comments Are Kept
+	c := New("test/bad/duplicate_key.cfg")
+	_, text := roundTrip(assert, c, 0)
+	assert.Equal("# Duplicate keys cannot be retrieved using a call to Get()\n# The method GetN() must be used\nfruits   apple\nfruits   pear\n", text)
+
+	c = New("test/test.cfg")
+	d, _ := roundTrip(assert, c, 12)
+	assert.True(diffEntries(c, d).Empty())
+	assert.Equal("/usr/share/lib", d.Get("lib"))
+	assert.Equal("36", d.Get("height"))
+
+	// Quoted And Multi-Line Values Read Back As Is
+	c = New("test/quoted.cfg")
+	d, text = roundTrip(assert, c, 8)
+	assert.True(diffEntries(c, d).Empty())
+	assert.Contains(text, `"  two  spaces  "`)
+	assert.Contains(text, `"Dear Bruce,\n\n  # Not a comment"`)
+	assert.Contains(text, "hex         #ff0000\n")
+	assert.Contains(text, `title       "\"Big\" thing"`)
+}
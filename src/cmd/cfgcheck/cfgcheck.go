@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"github.com/enova/tokyo/src/alert"
+	"github.com/enova/tokyo/src/cfg"
+	"os"
+)
+
+func main() {
+	spec := spec()
+	args := spec.Parse()
+
+	// Files
+	if args.Size() < 2 {
+		spec.Fail("Missing FILE")
+	}
+
+	// Schema (Optional)
+	var schema *cfg.Schema
+	if args.HasOpt("schema") {
+		var err error
+		schema, err = cfg.LoadSchema(args.GetOpt("schema"))
+		if err != nil {
+			alert.Cerr(err.Error())
+			os.Exit(2)
+		}
+	}
+
+	// Check Each Config File
+	failed := false
+	for a := 1; a < args.Size(); a++ {
+		filename := args.Get(a)
+
+		// Parse
+		c, err := cfg.Load(filename)
+		if err != nil {
+			fmt.Println(err.Error())
+			failed = true
+			continue
+		}
+
+		// Validate
+		if schema != nil {
+			for _, p := range c.Validate(schema) {
+
+				// Missing Keys Have No Location (Use The Filename)
+				if p.Origin == "" {
+					fmt.Println(filename + ": " + p.Error())
+				} else {
+					fmt.Println(p.Error())
+				}
+				failed = true
+			}
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/enova/tokyo/src/args"
+)
+
+func spec() *args.Spec {
+	s := args.NewSpec("cfgcheck")
+	s.UsageExit = 2
+	s.Usage = "cfgcheck [options] FILE..."
+	s.Add(args.Opt{Name: "schema", Type: args.TypeString, Placeholder: "FILE", Help: "Validate against the schema in FILE"})
+	s.About = `
+Checks that each config file parses (including all #INCLUDEs) and,
+if a schema is supplied, that it satisfies the schema. Each problem
+is printed on its own line. Exits with 1 if there are problems and
+2 if the schema itself can't be read or the arguments are invalid.
+
+Examples:
+
+  # Check that configs parse
+  cfgcheck prod.cfg staging.cfg
+
+  # Check configs against a schema
+  cfgcheck -schema=app.schema prod.cfg staging.cfg
+`
+	return s
+}
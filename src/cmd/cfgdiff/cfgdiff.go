@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/enova/tokyo/src/alert"
+	"github.com/enova/tokyo/src/cfg"
+	"github.com/mgutz/ansi"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	spec := spec()
+	args := spec.Parse()
+
+	// Files
+	if args.Size() != 3 {
+		spec.Fail("Expected two files: FILE1 FILE2")
+	}
+
+	// Parse Both Configs
+	a := load(args.Get(1))
+	b := load(args.Get(2))
+	diffs := cfg.Diff(a, b)
+
+	// JSON
+	if args.IsOn("json") {
+		if diffs == nil {
+			diffs = []cfg.Difference{}
+		}
+		text, err := json.MarshalIndent(diffs, "", "  ")
+		if err != nil {
+			alert.Cerr(err.Error())
+			os.Exit(2)
+		}
+		fmt.Println(string(text))
+	}
+
+	// Text
+	if !args.IsOn("json") {
+		color := !args.IsOn("nocolor")
+		for _, d := range diffs {
+			for _, v := range d.Old {
+				fmt.Println(line("-", d.Key, v, "red", color))
+			}
+			for _, v := range d.New {
+				fmt.Println(line("+", d.Key, v, "green", color))
+			}
+		}
+	}
+
+	if len(diffs) > 0 {
+		os.Exit(1)
+	}
+}
+
+// Load a config (exit with 2 on failure)
+func load(filename string) *cfg.Config {
+	c, err := cfg.Load(filename)
+	if err != nil {
+		alert.Cerr(err.Error())
+		os.Exit(2)
+	}
+	return c
+}
+
+// Format a single value (quoted if it contains line-breaks, tabs etc.)
+func line(sign, key, val, color string, colored bool) string {
+	if strings.TrimSpace(val) != val || strings.ContainsAny(val, "\n\r\t") {
+		val = strconv.Quote(val)
+	}
+
+	text := sign + " " + key + " " + val
+	if colored {
+		text = ansi.Color(text, color)
+	}
+	return text
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"github.com/enova/tokyo/src/args"
+)
+
+func spec() *args.Spec {
+	s := args.NewSpec("cfgdiff")
+	s.UsageExit = 2
+	s.Usage = "cfgdiff [options] FILE1 FILE2"
+	s.Add(args.Opt{Name: "json", Help: "Print the differences as JSON"})
+	s.Add(args.Opt{Name: "nocolor", Help: "Don't color the output"})
+	s.About = `
+Compares the effective configuration of two config files (after all
+#INCLUDEs, #DEFINEs and references are applied). Removed values are
+prefixed with -, added values with +. A changed key shows its old
+values followed by its new values. Values read from references are
+shown unresolved. Exits with 0 if the configs are identical, 1 if
+they differ and 2 if either can't be parsed or the arguments are
+invalid.
+
+Examples:
+
+  # Compare two environments
+  cfgdiff staging.cfg prod.cfg
+
+  # Compare as JSON
+  cfgdiff -json staging.cfg prod.cfg
+`
+	return s
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/enova/tokyo/src/alert"
+	"github.com/enova/tokyo/src/cfg"
+	"os"
+)
+
+func main() {
+	spec := spec()
+	args := spec.Parse()
+	asJSON := args.IsOn("json")
+
+	// File And Command
+	if args.Size() < 3 {
+		spec.Fail("Missing FILE or command")
+	}
+
+	command := args.Get(2)
+	var arg string
+	if args.Size() > 3 {
+		arg = args.Get(3)
+	}
+
+	// Number Of Arguments (KEY Or PREFIX)
+	min, max := 0, 0
+	switch command {
+	case "get", "descend":
+		min, max = 1, 1
+	case "keys":
+		max = 1
+	case "dump":
+	default:
+		spec.Fail("Unknown command: " + command)
+	}
+	if n := args.Size() - 3; n < min || n > max {
+		spec.Fail("Wrong number of arguments for " + command)
+	}
+
+	// Parse
+	c, err := cfg.Load(args.Get(1))
+	if err != nil {
+		alert.Cerr(err.Error())
+		os.Exit(2)
+	}
+
+	switch command {
+
+	case "get":
+		if !c.Has(arg) {
+			missing("Missing key: " + arg)
+		}
+		vals := values(c, arg)
+		if asJSON {
+			printJSON(jsonValue(vals))
+			break
+		}
+		for _, v := range vals {
+			fmt.Println(v)
+		}
+
+	case "keys":
+		var keys []string
+		if arg == "" {
+			for _, n := range c.Tree().Children {
+				keys = append(keys, n.Name)
+			}
+		} else {
+			keys = c.SubKeys(arg)
+		}
+		if len(keys) == 0 {
+			missing("Missing prefix: " + arg)
+		}
+		if asJSON {
+			printJSON(keys)
+			break
+		}
+		for _, k := range keys {
+			fmt.Println(k)
+		}
+
+	case "descend":
+		if !c.HasPrefix(arg) {
+			missing("Missing prefix: " + arg)
+		}
+		dump(c.Descend(arg), asJSON)
+
+	case "dump":
+		dump(c, asJSON)
+	}
+}
+
+// Print the message and exit with 1
+func missing(msg string) {
+	alert.Cerr(msg)
+	os.Exit(1)
+}
+
+// Returns all values of the key (in order)
+func values(c *cfg.Config, key string) []string {
+	var result []string
+	for i := 0; i < c.Size(key); i++ {
+		result = append(result, c.GetN(i, key))
+	}
+	return result
+}
+
+// A single value is a JSON string, repeated values are a list
+func jsonValue(vals []string) interface{} {
+	if len(vals) == 1 {
+		return vals[0]
+	}
+	return vals
+}
+
+// Print the whole config
+func dump(c *cfg.Config, asJSON bool) {
+	if !asJSON {
+		if _, err := c.WriteTo(os.Stdout); err != nil {
+			alert.Cerr(err.Error())
+			os.Exit(2)
+		}
+		return
+	}
+
+	// Object (Keys In Order Of First Appearance, References Unresolved)
+	var buffer bytes.Buffer
+	buffer.WriteString("{")
+	c.Tree().Walk(func(n *cfg.Node, depth int) bool {
+		if len(n.Values) == 0 {
+			return true
+		}
+		if buffer.Len() > 1 {
+			buffer.WriteString(",")
+		}
+		key, _ := json.Marshal(n.Key)
+		val, _ := json.Marshal(jsonValue(n.Raw))
+		buffer.Write(key)
+		buffer.WriteString(":")
+		buffer.Write(val)
+		return true
+	})
+	buffer.WriteString("}")
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buffer.Bytes(), "", "  "); err != nil {
+		alert.Cerr(err.Error())
+		os.Exit(2)
+	}
+	fmt.Println(out.String())
+}
+
+// Print the value as indented JSON
+func printJSON(v interface{}) {
+	text, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		alert.Cerr(err.Error())
+		os.Exit(2)
+	}
+	fmt.Println(string(text))
+}
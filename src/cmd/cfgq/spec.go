@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/enova/tokyo/src/args"
+)
+
+func spec() *args.Spec {
+	s := args.NewSpec("cfgq")
+	s.UsageExit = 2
+	s.Usage = `cfgq [options] FILE get KEY         Print the value of KEY (one line per value
+                                    if the key is repeated)
+cfgq [options] FILE keys [PREFIX]   Print the sub-keys of PREFIX (the first
+                                    segments of all keys if PREFIX is omitted)
+cfgq [options] FILE descend PREFIX  Print the entries below PREFIX (with the
+                                    prefix removed)
+cfgq [options] FILE dump            Print the whole config`
+	s.Add(args.Opt{Name: "json", Help: "Print the result as JSON"})
+	s.About = `
+Loads FILE (applying #INCLUDEs and #DEFINEs) and queries it. The
+output of descend and dump is in the config format unless -json is
+supplied, in which case it is an object mapping each key to its value
+(or to a list of values if the key is repeated). Either way, values
+read from references are left unresolved. Exits with 1 if the key or
+prefix is missing and 2 if the file can't be parsed or the arguments are
+invalid.
+
+Examples:
+
+  # Read a value in a shell script
+  host=$(cfgq prod.cfg get db.us.host)
+
+  # List the databases
+  cfgq prod.cfg keys db
+
+  # Write the expanded config for the US database
+  cfgq prod.cfg descend db.us > us.cfg
+
+  # Everything as JSON
+  cfgq --json prod.cfg dump
+`
+	return s
+}
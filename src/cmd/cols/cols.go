@@ -4,25 +4,17 @@ import (
 	"bufio"
 	"fmt"
 	"github.com/enova/tokyo/src/alert"
-	"github.com/enova/tokyo/src/args"
-	"github.com/enova/tokyo/src/lax"
 	"os"
 	"strings"
 )
 
 func main() {
-	args := args.New(os.Args)
-
-	// Help
-	if args.IsOn("h") {
-		fmt.Fprintf(os.Stderr, usage())
-		os.Exit(0)
-	}
+	var opts options
+	spec(&opts).Parse()
 
 	// Read Columns
 	var cols []uint32
-	for a := 1; a < args.Size(); a++ {
-		col := lax.ParseUint32(args.Get(a))
+	for _, col := range opts.Cols {
 		if col <= 0 {
 			alert.Cerr("Columns start at 1")
 			os.Exit(1)
@@ -31,18 +23,8 @@ func main() {
 		cols = append(cols, col-1)
 	}
 
-	// Skip Header Lines (Optional)
-	var skip int
-
-	// Option: -s (Skip One Header Line)
-	if args.IsOn("s") {
-		skip = 1
-	}
-
-	// Option: -s=N (Skip N Header Lines)
-	if args.HasOpt("s") {
-		skip = args.GetOptI("s")
-	}
+	// Skip Header Lines: -s (One Line) Or -s=N (N Lines)
+	skip := opts.Skip
 
 	// Read Stdin
 	scanner := bufio.NewScanner(os.Stdin)
@@ -57,7 +39,7 @@ func main() {
 
 		var tokens []string
 
-		if args.IsOn("space") {
+		if opts.Space {
 
 			// Split By Whitespace
 			tokens = strings.Fields(line)
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/enova/tokyo/src/args"
+)
+
+// Options are set from the command line
+type options struct {
+	Space bool     `arg:"space" help:"Split using whitespace"`
+	Skip  int      `arg:"s,skip" implied:"1" default:"0" placeholder:"N" help:"Skip first N lines (-s skips the first line)"`
+	Cols  []uint32 `arg:"1..." placeholder:"COLUMN"`
+}
+
+func spec(opts *options) *args.Spec {
+	s := args.NewSpec("cols")
+	s.EnvPrefix = "COLS"
+	s.Bind(opts)
+	s.AddCompletion()
+	s.About = `
+Examples:
+
+  # Extract columns 2 and 13
+  cat junk.csv | cols 2 13
+
+  # Skip the first line
+  cat junk.csv | cols 2 13 -s
+
+  # Skip the first two lines
+  cat junk.csv | cols 2 13 -s=2
+
+  # Split using whitespace
+  cat junk.txt | cols 2 13 -s=2 -space
+
+  # Skip the first two lines (unless -s is given)
+  export COLS_SKIP=2
+  cat junk.csv | cols 2 13
+
+  # Enable tab completion (bash)
+  source <(cols -completion=bash)
+`
+	return s
+}
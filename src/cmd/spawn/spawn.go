@@ -3,14 +3,13 @@ package main
 import (
 	"bufio"
 	"fmt"
-	"github.com/enova/tokyo/src/args"
 	"github.com/enova/tokyo/src/lax"
 	"github.com/enova/tokyo/src/spawn"
 	"os"
 )
 
 func main() {
-	args := args.New(os.Args)
+	args := spec().Parse()
 
 	if args.Size() != 2 {
 		fmt.Fprintf(os.Stderr, "Needs MaxSpawn (0 => Spawn All, Reads commands from Stdin)\n")
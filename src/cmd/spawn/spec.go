@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/enova/tokyo/src/args"
+)
+
+func spec() *args.Spec {
+	s := args.NewSpec("spawn").AddCompletion()
+	s.Usage = "spawn MAXSPAWN < commands.txt"
+	s.About = `
+Reads shell commands from stdin (one per line) and runs them, at most
+MAXSPAWN at a time (0 runs them all at once). A warning is displayed
+for each command that exits with a non-zero exit-code.
+
+Examples:
+
+  # Run the commands 5 at a time
+  cat commands.txt | spawn 5
+
+  # Enable tab completion (bash)
+  source <(spawn -completion=bash)
+`
+	return s
+}
@@ -12,6 +12,9 @@ type W struct {
 	obj      interface{}
 	location string
 	failure  string
+	parent   *W          // Instance this one descended from (nil for the root)
+	slot     interface{} // Key (string) or index (int) within the parent
+	origin   interface{} // Value of the slot this one was taken from (see store)
 }
 
 // New ...
@@ -27,7 +30,7 @@ func (w *W) String() string {
 }
 
 // Pretty returns an indented, multi-line string representation of the underlying object
-// (invokes json.MarshalIndent)
+// (invokes json.MarshalIndent), including any changes (see Set)
 func (w *W) Pretty() string {
 	bytes, _ := json.MarshalIndent(w.obj, "", "  ")
 	return string(bytes)
@@ -84,6 +87,7 @@ func (w *W) Key(key string) *W {
 
 	// Append Location
 	child.obj = value
+	child.parent, child.slot, child.origin = w, key, value
 	child.appendLocation("key: " + key)
 	return child
 }
@@ -137,6 +141,7 @@ func (w *W) At(i int) *W {
 
 	// Append Location
 	child.obj = value
+	child.parent, child.slot, child.origin = w, i, value
 	child.appendLocation("at: " + strconv.Itoa(i))
 	return child
 }
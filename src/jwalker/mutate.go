@@ -0,0 +1,277 @@
+package jwalker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// MaxPadding is the largest number of nulls Set (or SetAt) adds to extend
+// an array up to an index beyond its end
+const MaxPadding = 1024
+
+// Edit returns the new value for an existing (or missing) value, whether
+// to keep it (false removes it from its map or array), and a failure
+type edit func(old interface{}, exists bool) (val interface{}, keep bool, failure string)
+
+// Set sets the value at the supplied path of keys and indexes, e.g.
+// "$.owner.address.city" or "$.teams[3]" ("$" replaces the instance's
+// value). Missing maps and arrays along the path are created, and arrays
+// are extended with nulls up to the index (adding at most MaxPadding
+// nulls, beyond which the index is out of range). The value may be anything
+// json.Marshal accepts (numbers are stored as float64, as by New). It
+// returns the instance, so changes can be chained:
+//
+//	w.Set("$.owner.name", "gopher").Delete("$.owner.ssid").Append("$.teams", "yellow")
+//
+// If the path passes through a value of the wrong type (e.g. a key of an
+// array) or is invalid, it returns a failed instance whose Location() and
+// Failure() show where and why, as Key and At do.
+func (w *W) Set(path string, value interface{}) *W {
+	if !w.Ok() {
+		return w
+	}
+
+	val, err := encode(value)
+	if err != nil {
+		return w.failed("set: " + path + " (" + err.Error() + ")")
+	}
+
+	return w.update("set", path, true, func(old interface{}, exists bool) (interface{}, bool, string) {
+		return val, true, ""
+	})
+}
+
+// SetAt sets the element at the supplied index of the instance's array
+// (negative indexes count from the end), extending the array with nulls
+// if needed. It returns the instance, or a failed instance as Set does.
+func (w *W) SetAt(i int, value interface{}) *W {
+	return w.Set("$["+strconv.Itoa(i)+"]", value)
+}
+
+// Append appends the value to the array at the supplied path ("$" for
+// the instance's array), creating the array (and any missing maps and
+// arrays along the path) if needed. It returns the instance, or a failed
+// instance as Set does.
+func (w *W) Append(path string, value interface{}) *W {
+	if !w.Ok() {
+		return w
+	}
+
+	val, err := encode(value)
+	if err != nil {
+		return w.failed("append: " + path + " (" + err.Error() + ")")
+	}
+
+	return w.update("append", path, true, func(old interface{}, exists bool) (interface{}, bool, string) {
+		if !exists || old == nil {
+			return []interface{}{val}, true, ""
+		}
+
+		array, ok := old.([]interface{})
+		if !ok {
+			return nil, false, fmt.Sprintf("append: %s (not an array, rather it is of type %v)", path, reflect.TypeOf(old))
+		}
+		return append(array, val), true, ""
+	})
+}
+
+// Delete removes the key (or array element) at the supplied path, e.g.
+// "$.owner.ssid". Deleting something that doesn't exist changes nothing.
+// It returns the instance, or a failed instance as Set does.
+func (w *W) Delete(path string) *W {
+	if !w.Ok() {
+		return w
+	}
+
+	return w.update("delete", path, false, func(old interface{}, exists bool) (interface{}, bool, string) {
+		return nil, false, ""
+	})
+}
+
+// Bytes returns the JSON encoding of the instance's value, including any
+// changes (see Set)
+func (w *W) Bytes() ([]byte, error) {
+	return json.Marshal(w.obj)
+}
+
+// Applies the edit to the value at the path (creating missing maps and
+// arrays along the way if create is set)
+func (w *W) update(op, path string, create bool, fn edit) *W {
+	steps, err := parsePath(path, "$")
+	if err == nil && !plain(steps) {
+		err = errors.New("only keys and indexes are allowed")
+	}
+	if err != nil {
+		return w.failed(op + ": " + path + " (" + err.Error() + ")")
+	}
+
+	// The Instance Itself
+	if len(steps) == 0 && op == "delete" {
+		return w.failed("delete: " + path + " (can't delete the instance itself)")
+	}
+
+	at := &W{location: w.location}
+	obj, _, failure := apply(w.obj, true, steps, create, fn, at)
+	if failure != "" {
+		at.failure = failure
+		return at
+	}
+
+	w.obj = obj
+	w.store()
+	return w
+}
+
+// Returns the value with the edit applied at the end of the steps. The
+// location of the steps taken is appended to at.
+func apply(obj interface{}, exists bool, steps []step, create bool, fn edit, at *W) (interface{}, bool, string) {
+
+	// End Of The Path
+	if len(steps) == 0 {
+		return fn(obj, exists)
+	}
+
+	// Nothing To Descend Into
+	if (!exists || obj == nil) && !create {
+		return obj, exists, ""
+	}
+
+	switch sel := steps[0].sel.(type) {
+
+	// Key
+	case keySel:
+		key := sel[0]
+
+		if !exists || obj == nil {
+			obj = map[string]interface{}{}
+		}
+		mapped, ok := obj.(map[string]interface{})
+		if !ok {
+			return nil, false, fmt.Sprintf("key: %s (not a map, rather it is of type %v)", key, reflect.TypeOf(obj))
+		}
+
+		at.appendLocation("key: " + key)
+		child, found := mapped[key]
+		val, keep, failure := apply(child, found, steps[1:], create, fn, at)
+		if failure != "" {
+			return nil, false, failure
+		}
+
+		if keep {
+			mapped[key] = val
+		} else {
+			delete(mapped, key)
+		}
+		return mapped, true, ""
+
+	// Index
+	case indexSel:
+		i := sel[0]
+
+		if !exists || obj == nil {
+			obj = []interface{}{}
+		}
+		array, ok := obj.([]interface{})
+		if !ok {
+			return nil, false, fmt.Sprintf("at: %d (not an array, rather it is of type %v)", i, reflect.TypeOf(obj))
+		}
+
+		// Negative Index (Counts From The End)
+		if i < 0 {
+			i += len(array)
+		}
+
+		// Missing Element (Nothing To Delete, Else Padded With Nulls)
+		found := i >= 0 && i < len(array)
+		if !found && !create {
+			return array, true, ""
+		}
+		if i < 0 || i-len(array) >= MaxPadding {
+			return nil, false, fmt.Sprintf("at: %d (out of range, size=%d)", sel[0], len(array))
+		}
+		for len(array) <= i {
+			array = append(array, nil)
+		}
+
+		at.appendLocation("at: " + strconv.Itoa(i))
+		val, keep, failure := apply(array[i], found, steps[1:], create, fn, at)
+		if failure != "" {
+			return nil, false, failure
+		}
+
+		if keep {
+			array[i] = val
+			return array, true, ""
+		}
+
+		// Deleted (Into A New Array, Leaving Instances Taken Before Intact)
+		kept := make([]interface{}, 0, len(array)-1)
+		kept = append(kept, array[:i]...)
+		return append(kept, array[i+1:]...), true, ""
+	}
+
+	return obj, exists, ""
+}
+
+// Writes the instance's value into its parent's map or array, in case it
+// was replaced (e.g. by a longer array). Nothing is written if the slot no
+// longer holds the value the instance was taken from (e.g. the key was
+// deleted, or an element before it was).
+func (w *W) store() {
+	if w.parent == nil {
+		return
+	}
+
+	switch slot := w.slot.(type) {
+	case string:
+		mapped, ok := w.parent.obj.(map[string]interface{})
+		if current, found := mapped[slot]; ok && found && same(current, w.origin) {
+			mapped[slot] = w.obj
+			w.origin = w.obj
+		}
+	case int:
+		array, ok := w.parent.obj.([]interface{})
+		if ok && slot < len(array) && same(array[slot], w.origin) {
+			array[slot] = w.obj
+			w.origin = w.obj
+		}
+	}
+}
+
+// Returns true if both are the same value (maps and arrays must be the
+// same instance, not just equal)
+func same(a, b interface{}) bool {
+	switch a.(type) {
+	case map[string]interface{}, []interface{}:
+		if reflect.TypeOf(a) != reflect.TypeOf(b) {
+			return false
+		}
+		x, y := reflect.ValueOf(a), reflect.ValueOf(b)
+		return x.Pointer() == y.Pointer() && x.Len() == y.Len()
+	}
+	return a == b
+}
+
+// Returns a failed instance at the instance's location
+func (w *W) failed(failure string) *W {
+	return &W{location: w.location, failure: failure}
+}
+
+// Returns the value as New would decode it (e.g. numbers as float64)
+func encode(value interface{}) (interface{}, error) {
+	if w, ok := value.(*W); ok {
+		value = w.obj
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	err = json.Unmarshal(b, &result)
+	return result, err
+}
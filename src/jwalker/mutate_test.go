@@ -0,0 +1,107 @@
+package jwalker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns the JSON encoding of the instance
+func encoded(assert *assert.Assertions, w *W) string {
+	b, err := w.Bytes()
+	assert.Nil(err)
+	return string(b)
+}
+
+func TestMutate(t *testing.T) {
+	assert := assert.New(t)
+
+	w, err := New([]byte(`{"name": "gopher", "ssid": 123, "teams": ["red"], "owner": {"age": 7}, "none": null}`))
+	assert.Nil(err)
+
+	// Set (Creating Maps And Arrays Along The Way)
+	result := w.Set("$.name", "tokyo").
+		Set("$.address.city", "Chicago").
+		Set("$.scores[2].value", 5).
+		Set("$.none.x", true).
+		Set("$.owner['full name']", map[string]string{"first": "Go"})
+	assert.True(result.Ok())
+	assert.Equal(w, result)
+
+	assert.Equal(`{"address":{"city":"Chicago"},"name":"tokyo","none":{"x":true},"owner":{"age":7,"full name":{"first":"Go"}},"scores":[null,null,{"value":5}],"ssid":123,"teams":["red"]}`, encoded(assert, w))
+
+	// Numbers Are Stored As By New
+	i, ok := w.Key("scores").At(2).KeyI("value")
+	assert.True(ok)
+	assert.Equal(5, i)
+
+	// Delete, Append And SetAt
+	w.Delete("$.ssid").Delete("$.nope").Delete("$.nope.deeper[3]").Delete("$.scores[0]").Delete("$.scores[9]").Delete("$.scores[-9]")
+	w.Append("$.teams", "blue").Append("$.tags", "new").Append("$.scores[-1].list", 1)
+	w.Key("teams").SetAt(3, "green").SetAt(-1, "yellow")
+	assert.Equal(`{"address":{"city":"Chicago"},"name":"tokyo","none":{"x":true},"owner":{"age":7,"full name":{"first":"Go"}},"scores":[null,{"list":[1],"value":5}],"tags":["new"],"teams":["red","blue",null,"yellow"]}`, encoded(assert, w))
+
+	// Changes Through Children (And Paths) Reach The Document
+	w.Key("teams").Append("$", "white")
+	w.Key("owner").Set("$", "nobody")
+	for _, city := range w.Path("$..city") {
+		city.Set("$", "REDACTED")
+	}
+	s, _ := w.Key("teams").AtS(4)
+	assert.Equal("white", s)
+	s, _ = w.KeyS("owner")
+	assert.Equal("nobody", s)
+	s, _ = w.Key("address").KeyS("city")
+	assert.Equal("REDACTED", s)
+
+	// Set A Whole Instance
+	other, _ := New([]byte(`{"x": [1, 2]}`))
+	w.Set("$.copy", other)
+	assert.Equal(2, w.Key("copy").Key("x").Len())
+
+	// Failures (With Their Locations)
+	for path, trace := range map[string]string{
+		"$.name.first":  "[location] => key: name [failure] => key: first (not a map, rather it is of type string)",
+		"$.teams.first": "[location] => key: teams [failure] => key: first (not a map, rather it is of type []interface {})",
+		"$.address[0]":  "[location] => key: address [failure] => at: 0 (not an array, rather it is of type map[string]interface {})",
+		"$.teams[-9]":   "[location] => key: teams [failure] => at: -9 (out of range, size=5)",
+		"$.teams[1029]": "[location] => key: teams [failure] => at: 1029 (out of range, size=5)",
+		"$..name":       "[location] =>  [failure] => set: $..name (only keys and indexes are allowed)",
+		"$.teams[":      "[location] =>  [failure] => set: $.teams[ (missing ] at position 8)",
+	} {
+		failed := w.Set(path, 1)
+		assert.False(failed.Ok(), path)
+		assert.Equal(trace, failed.Trace(), path)
+	}
+
+	assert.Equal("[location] => key: address [failure] => append: $.address (not an array, rather it is of type map[string]interface {})", w.Append("$.address", 1).Trace())
+	assert.Equal("[location] =>  [failure] => delete: $ (can't delete the instance itself)", w.Delete("$").Trace())
+	assert.Equal("[location] =>  [failure] => set: $.x (json: unsupported type: chan int)", w.Set("$.x", make(chan int)).Trace())
+
+	// Failures Propagate Through Chains (Leaving The Rest Unchanged)
+	failed := w.Set("$.name.first", 1).Set("$.later", 2)
+	assert.Equal("key: first (not a map, rather it is of type string)", failed.Failure())
+	assert.False(w.Key("later").Ok())
+
+	failed = w.Key("nope").Set("$.x", 1)
+	assert.Equal("key: nope (key does not exist)", failed.Failure())
+
+	// Deletes Don't Disturb Instances Taken Before
+	w, _ = New([]byte(`{"arr": [{"n": "a"}, {"n": "b"}, {"n": "c"}], "nums": [1, 2, 3]}`))
+	items := w.Path("$.arr[*]")
+	nums := w.Key("nums")
+	w.Delete("$.arr[0]").Delete("$.nums[0]")
+	items[1].Set("$.x", 1)
+	assert.Equal(`{"arr":[{"n":"b","x":1},{"n":"c"}],"nums":[2,3]}`, encoded(assert, w))
+	assert.Equal(`[1,2,3]`, encoded(assert, nums))
+
+	// Changes Through Stale Instances Are Dropped
+	nums.Append("$", 4)
+	items[0].Set("$", "gone")
+	assert.Equal(`{"arr":[{"n":"b","x":1},{"n":"c"}],"nums":[2,3]}`, encoded(assert, w))
+
+	// Pretty
+	w, _ = New([]byte(`{"a": 1}`))
+	w.Set("$.b", []int{2})
+	assert.Equal("{\n  \"a\": 1,\n  \"b\": [\n    2\n  ]\n}", w.Pretty())
+}
@@ -0,0 +1,760 @@
+package jwalker
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Step is a single step of a path: a selector applied to each current
+// value (or, for recursive descent, to each value and its descendants)
+type step struct {
+	recursive bool
+	sel       selector
+}
+
+// Selector selects children of a value
+type selector interface {
+	apply(w *W) []*W
+}
+
+// KeySel selects the values of the listed keys: .name or ['a','b']
+type keySel []string
+
+// WildcardSel selects all elements of an array or values of a map: .* or [*]
+type wildcardSel struct{}
+
+// IndexSel selects the listed array elements (negative counts from the end): [0,-1]
+type indexSel []int
+
+// SliceSel selects a range of array elements: [start:end:step]
+type sliceSel struct {
+	start, end, step int
+	hasStart, hasEnd bool
+}
+
+// FilterSel selects the elements (or map values) matching a condition: [?(@.x > 1)]
+type filterSel struct {
+	or [][]comparison // Any of the groups, in which all comparisons hold
+}
+
+// Comparison compares two operands (or checks that one exists, if op is empty)
+type comparison struct {
+	left, right operand
+	op          string
+}
+
+// Operand is a path relative to the element (@.x) or a literal
+type operand struct {
+	steps   []step      // Nil for a literal
+	literal interface{} // float64, string, bool or nil
+}
+
+// Path returns the values matching a JSONPath-style expression, e.g.
+//
+//	w.Path("$.loans[*].payments[?(@.amount > 100)].id")
+//
+// The expression starts at the instance ($) and supports:
+//
+//	.name ['name']   Key (['a','b'] for several keys)
+//	[2] [-1] [0,2]   Index (negative indexes count from the end)
+//	.* [*]           All elements of an array or values of a map
+//	..name ..*       Recursive descent (the value and all its descendants)
+//	[1:3] [::2]      Slice (start, end and step, as in Python)
+//	[?(@.x > 1)]     Filter: ==, !=, <, <=, >, >= against numbers, 'strings',
+//	                 true, false and null, [?(@.x)] for existence, combined
+//	                 with && and ||
+//
+// Each result has its own Location(), e.g. "key: loans | at: 0 | key: id".
+// Values of a map are visited in key order. Keys and indexes that don't
+// exist (or don't apply) are skipped, so the result may be empty. If the
+// instance has failed, or the expression is invalid, the result is a
+// single failed instance.
+func (w *W) Path(expr string) []*W {
+
+	if !w.Ok() {
+		return []*W{w}
+	}
+
+	steps, err := parsePath(expr, "$")
+	if err != nil {
+		child := &W{location: w.location}
+		child.failure = "path: " + expr + " (" + err.Error() + ")"
+		return []*W{child}
+	}
+
+	return walk([]*W{w}, steps)
+}
+
+// Applies the steps to the values
+func walk(current []*W, steps []step) []*W {
+	for _, s := range steps {
+		var next []*W
+		for _, w := range current {
+			nodes := []*W{w}
+			if s.recursive {
+				nodes = descendants(w)
+			}
+			for _, node := range nodes {
+				next = append(next, s.sel.apply(node)...)
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// Returns the value followed by all its descendants (depth first)
+func descendants(w *W) []*W {
+	result := []*W{w}
+	for _, child := range children(w) {
+		result = append(result, descendants(child)...)
+	}
+	return result
+}
+
+// Returns the elements of an array or the values of a map (in key order)
+func children(w *W) []*W {
+	var result []*W
+
+	switch {
+	case w.IsArray():
+		for i := 0; i < w.Len(); i++ {
+			result = append(result, w.At(i))
+		}
+	case w.IsMap():
+		keys := w.Keys()
+		sort.Strings(keys)
+		for _, key := range keys {
+			result = append(result, w.Key(key))
+		}
+	}
+
+	return result
+}
+
+func (s keySel) apply(w *W) []*W {
+	var result []*W
+	for _, key := range s {
+		if child := w.Key(key); child.Ok() {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+func (s wildcardSel) apply(w *W) []*W {
+	return children(w)
+}
+
+func (s indexSel) apply(w *W) []*W {
+	var result []*W
+	for _, i := range s {
+		if i < 0 {
+			i += w.Len()
+		}
+		if child := w.At(i); child.Ok() {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+func (s sliceSel) apply(w *W) []*W {
+	if !w.IsArray() {
+		return nil
+	}
+
+	// Bounds (Negative Counts From The End, As In Python)
+	size := w.Len()
+	bound := func(i, low, high int) int {
+		if i < 0 {
+			i += size
+		}
+		if i < low {
+			return low
+		}
+		if i > high {
+			return high
+		}
+		return i
+	}
+
+	var result []*W
+
+	// Forwards
+	if s.step > 0 {
+		start, end := 0, size
+		if s.hasStart {
+			start = bound(s.start, 0, size)
+		}
+		if s.hasEnd {
+			end = bound(s.end, 0, size)
+		}
+		for i := start; i < end; i += s.step {
+			result = append(result, w.At(i))
+		}
+		return result
+	}
+
+	// Backwards
+	start, end := size-1, -1
+	if s.hasStart {
+		start = bound(s.start, -1, size-1)
+	}
+	if s.hasEnd {
+		end = bound(s.end, -1, size-1)
+	}
+	for i := start; i > end; i += s.step {
+		result = append(result, w.At(i))
+	}
+	return result
+}
+
+func (s filterSel) apply(w *W) []*W {
+	var result []*W
+	for _, child := range children(w) {
+		if s.matches(child) {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+// Returns true if all comparisons of any group hold for the element
+func (s filterSel) matches(w *W) bool {
+	for _, group := range s.or {
+		ok := true
+		for _, c := range group {
+			if !c.holds(w) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the comparison holds for the element
+func (c comparison) holds(w *W) bool {
+	left, ok := c.left.value(w)
+
+	// Existence
+	if c.op == "" {
+		return ok
+	}
+
+	right, ok2 := c.right.value(w)
+	if !ok || !ok2 {
+		return false
+	}
+
+	switch c.op {
+	case "==":
+		return reflect.DeepEqual(left, right)
+	case "!=":
+		return !reflect.DeepEqual(left, right)
+	}
+
+	// Ordering (Numbers Or Strings)
+	var order int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return false
+		}
+		switch {
+		case l < r:
+			order = -1
+		case l > r:
+			order = 1
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return false
+		}
+		order = strings.Compare(l, r)
+	default:
+		return false
+	}
+
+	switch c.op {
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	}
+	return order >= 0
+}
+
+// Returns the operand's value for the element (false if the path matches nothing)
+func (o operand) value(w *W) (interface{}, bool) {
+	if o.steps == nil {
+		return o.literal, true
+	}
+
+	found := walk([]*W{w}, o.steps)
+	if len(found) == 0 {
+		return nil, false
+	}
+	return found[0].obj, true
+}
+
+// Returns true if each step is a single key or index, as in $.a[2].b
+func plain(steps []step) bool {
+	for _, s := range steps {
+		key, isKey := s.sel.(keySel)
+		index, isIndex := s.sel.(indexSel)
+		if s.recursive || !(isKey && len(key) == 1 || isIndex && len(index) == 1) {
+			return false
+		}
+	}
+	return true
+}
+
+///////////
+// Parse //
+///////////
+
+// Parser reads an expression from left to right
+type parser struct {
+	s   string
+	pos int
+}
+
+// Parses a path starting with the supplied root ($ or @), which may be omitted
+func parsePath(expr, root string) ([]step, error) {
+	p := &parser{s: expr}
+	if strings.HasPrefix(expr, root) {
+		p.pos = len(root)
+	}
+
+	steps := []step{}
+	for !p.done() {
+		s := step{}
+
+		switch {
+
+		// Recursive Descent: ..name, ..*, ..[...]
+		case p.skip(".."):
+			s.recursive = true
+			if p.peek() != '[' {
+				sel, err := p.member()
+				if err != nil {
+					return nil, err
+				}
+				s.sel = sel
+				break
+			}
+			p.pos++
+			sel, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			s.sel = sel
+
+		// Member: .name, .*
+		case p.skip("."):
+			sel, err := p.member()
+			if err != nil {
+				return nil, err
+			}
+			s.sel = sel
+
+		// Bracket: [...]
+		case p.skip("["):
+			sel, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			s.sel = sel
+
+		default:
+			return nil, p.fail("unexpected character")
+		}
+
+		steps = append(steps, s)
+	}
+
+	return steps, nil
+}
+
+// Parses a name or * (after a dot)
+func (p *parser) member() (selector, error) {
+	if p.skip("*") {
+		return wildcardSel{}, nil
+	}
+
+	start := p.pos
+	for !p.done() && !strings.ContainsRune(".[", rune(p.peek())) {
+		p.pos++
+	}
+
+	if p.pos == start {
+		return nil, p.fail("missing name")
+	}
+	return keySel{p.s[start:p.pos]}, nil
+}
+
+// Parses the contents of brackets (after the opening bracket)
+func (p *parser) bracket() (selector, error) {
+	start := p.pos
+	end := p.closing(start)
+	if end < 0 {
+		return nil, p.fail("missing ]")
+	}
+	p.pos = end + 1
+	text := strings.TrimSpace(p.s[start:end])
+
+	switch {
+
+	// Wildcard
+	case text == "*":
+		return wildcardSel{}, nil
+
+	// Filter
+	case strings.HasPrefix(text, "?(") && strings.HasSuffix(text, ")"):
+		return parseFilter(text[2 : len(text)-1])
+
+	// Keys
+	case strings.HasPrefix(text, "'") || strings.HasPrefix(text, `"`):
+		var result keySel
+		for _, item := range split(text, ',') {
+			key, ok := unquote(strings.TrimSpace(item))
+			if !ok {
+				return nil, errors.New("bad key: " + item)
+			}
+			result = append(result, key)
+		}
+		return result, nil
+
+	// Slice
+	case strings.Contains(text, ":"):
+		return parseSlice(text)
+	}
+
+	// Indexes
+	var result indexSel
+	for _, item := range strings.Split(text, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil {
+			return nil, errors.New("bad index: " + item)
+		}
+		result = append(result, i)
+	}
+	return result, nil
+}
+
+// Parses a slice: start:end or start:end:step (each part optional)
+func parseSlice(text string) (selector, error) {
+	parts := strings.Split(text, ":")
+	if len(parts) > 3 {
+		return nil, errors.New("bad slice: " + text)
+	}
+
+	result := sliceSel{step: 1}
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil || (i == 2 && n == 0) {
+			return nil, errors.New("bad slice: " + text)
+		}
+
+		switch i {
+		case 0:
+			result.start, result.hasStart = n, true
+		case 1:
+			result.end, result.hasEnd = n, true
+		case 2:
+			result.step = n
+		}
+	}
+
+	return result, nil
+}
+
+// Parses the condition of a filter, e.g. @.amount > 100 && @.paid
+func parseFilter(text string) (selector, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var result filterSel
+	var group []comparison
+
+	for i := 0; i < len(tokens); {
+		left, err := parseOperand(tokens[i])
+		if err != nil {
+			return nil, err
+		}
+		c := comparison{left: left}
+		i++
+
+		// Operator And Right Operand
+		if i < len(tokens) && isComparison(tokens[i]) {
+			if i+1 >= len(tokens) {
+				return nil, errors.New("missing operand after " + tokens[i])
+			}
+			c.op = tokens[i]
+			c.right, err = parseOperand(tokens[i+1])
+			if err != nil {
+				return nil, err
+			}
+			i += 2
+		}
+
+		if c.op == "" && left.steps == nil {
+			return nil, errors.New("bad condition: " + text)
+		}
+		group = append(group, c)
+
+		// && Or ||
+		if i < len(tokens) {
+			switch tokens[i] {
+			case "&&":
+			case "||":
+				result.or = append(result.or, group)
+				group = nil
+			default:
+				return nil, errors.New("bad condition: " + text)
+			}
+			i++
+			if i == len(tokens) {
+				return nil, errors.New("bad condition: " + text)
+			}
+		}
+	}
+
+	if len(group) == 0 {
+		return nil, errors.New("empty filter")
+	}
+	result.or = append(result.or, group)
+
+	return result, nil
+}
+
+// Parses an operand: @.path, a number, a 'string', true, false or null
+func parseOperand(token string) (operand, error) {
+	switch {
+	case strings.HasPrefix(token, "@"):
+		steps, err := parsePath(token, "@")
+		return operand{steps: steps}, err
+	case token == "true":
+		return operand{literal: true}, nil
+	case token == "false":
+		return operand{literal: false}, nil
+	case token == "null":
+		return operand{literal: nil}, nil
+	}
+
+	if s, ok := unquote(token); ok {
+		return operand{literal: s}, nil
+	}
+
+	if f, err := strconv.ParseFloat(token, 64); err == nil {
+		return operand{literal: f}, nil
+	}
+
+	return operand{}, errors.New("bad operand: " + token)
+}
+
+// Returns true for a comparison operator
+func isComparison(token string) bool {
+	switch token {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// Splits a condition into operands and operators
+func tokenize(text string) ([]string, error) {
+	var result []string
+	p := &parser{s: text}
+
+	for {
+		for !p.done() && (p.peek() == ' ' || p.peek() == '\t') {
+			p.pos++
+		}
+		if p.done() {
+			return result, nil
+		}
+
+		// Operator
+		operator := ""
+		for _, op := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">"} {
+			if p.skip(op) {
+				operator = op
+				break
+			}
+		}
+		if operator != "" {
+			result = append(result, operator)
+			continue
+		}
+
+		// Operand (Up To A Space Or Operator, Outside Quotes And Brackets)
+		start := p.pos
+		depth := 0
+		for !p.done() {
+			c := p.peek()
+			if c == '\'' || c == '"' {
+				end := quoteEnd(p.s, p.pos)
+				if end < 0 {
+					return nil, errors.New("missing quote")
+				}
+				p.pos = end + 1
+				continue
+			}
+			if depth == 0 && strings.ContainsRune(" \t=!<>&|", rune(c)) {
+				break
+			}
+			switch c {
+			case '[':
+				depth++
+			case ']':
+				depth--
+			}
+			p.pos++
+		}
+
+		if p.pos == start {
+			return nil, errors.New("unexpected character: " + string(p.peek()))
+		}
+		result = append(result, p.s[start:p.pos])
+	}
+}
+
+// Returns the position of the bracket closing the one before the supplied
+// position, skipping quotes and nested brackets (-1 if none)
+func (p *parser) closing(pos int) int {
+	depth := 0
+	for i := pos; i < len(p.s); i++ {
+		switch p.s[i] {
+		case '\'', '"':
+			end := quoteEnd(p.s, i)
+			if end < 0 {
+				return -1
+			}
+			i = end
+		case '[':
+			depth++
+		case ']':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// Returns the position of the quote closing the one at the supplied
+// position (-1 if none)
+func quoteEnd(s string, pos int) int {
+	for i := pos + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case s[pos]:
+			return i
+		}
+	}
+	return -1
+}
+
+// Splits the text at the separator, outside quotes
+func split(text string, sep byte) []string {
+	var result []string
+	start := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\'', '"':
+			if end := quoteEnd(text, i); end >= 0 {
+				i = end
+			}
+		case sep:
+			result = append(result, text[start:i])
+			start = i + 1
+		}
+	}
+	return append(result, text[start:])
+}
+
+// Returns the contents of a 'single' or "double" quoted string (with the
+// escapes of JSON, plus \' in single quotes)
+func unquote(text string) (string, bool) {
+	if len(text) < 2 || (text[0] != '\'' && text[0] != '"') || quoteEnd(text, 0) != len(text)-1 {
+		return "", false
+	}
+
+	// Single Quotes => Double Quotes
+	quoted := text
+	if text[0] == '\'' {
+		quoted = `"`
+		body := text[1 : len(text)-1]
+		for i := 0; i < len(body); i++ {
+			switch {
+			case body[i] == '\\' && i+1 < len(body) && body[i+1] == '\'':
+				quoted += "'"
+				i++
+			case body[i] == '\\' && i+1 < len(body):
+				quoted += body[i : i+2]
+				i++
+			case body[i] == '"':
+				quoted += `\"`
+			default:
+				quoted += body[i : i+1]
+			}
+		}
+		quoted += `"`
+	}
+
+	var result string
+	if err := json.Unmarshal([]byte(quoted), &result); err != nil {
+		return "", false
+	}
+	return result, true
+}
+
+// Returns true once the whole expression has been read
+func (p *parser) done() bool {
+	return p.pos >= len(p.s)
+}
+
+// Returns the current character (zero at the end)
+func (p *parser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+// Skips the prefix if the expression continues with it
+func (p *parser) skip(prefix string) bool {
+	if strings.HasPrefix(p.s[p.pos:], prefix) {
+		p.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+// Returns an error describing the current position
+func (p *parser) fail(reason string) error {
+	return errors.New(reason + " at position " + strconv.Itoa(p.pos))
+}
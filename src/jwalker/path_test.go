@@ -0,0 +1,115 @@
+package jwalker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns the string values of the instances
+func ids(list []*W) []string {
+	result := []string{}
+	for _, w := range list {
+		s, _ := w.S()
+		result = append(result, s)
+	}
+	return result
+}
+
+// Returns the locations of the instances
+func locations(list []*W) []string {
+	result := []string{}
+	for _, w := range list {
+		result = append(result, w.Location())
+	}
+	return result
+}
+
+func TestPath(t *testing.T) {
+	assert := assert.New(t)
+
+	w, err := New(ReadFile(assert, "test/loans.json"))
+	assert.Nil(err)
+
+	// Filter
+	found := w.Path("$.loans[*].payments[?(@.amount > 100)].id")
+	assert.Equal([]string{"P2", "P3", "P5"}, ids(found))
+	assert.Equal([]string{
+		"key: loans | at: 0 | key: payments | at: 1 | key: id",
+		"key: loans | at: 0 | key: payments | at: 2 | key: id",
+		"key: loans | at: 1 | key: payments | at: 1 | key: id",
+	}, locations(found))
+
+	for expr, want := range map[string][]string{
+
+		// Keys And Indexes
+		"$.owner":                 {"gopher"},
+		"$['owner']":              {"gopher"},
+		`$["loans"][1].id`:        {"L2"},
+		"$.loans[-1].id":          {"L3"},
+		"$.loans[0,2].id":         {"L1", "L3"},
+		"$.loans[0]['id','nope']": {"L1"},
+		"$.loans[7].id":           {},
+		"$.owner.id":              {},
+		"$.owner[0]":              {},
+
+		// Wildcards And Recursive Descent
+		"$.loans.*.id":           {"L1", "L2", "L3"},
+		"$..payments[0].id":      {"P1", "P4"},
+		"$..[?(@.paid)].id":      {"P1", "P2", "P3", "P5"},
+		"$.loans[2]..id":         {"L3"},
+		"$['weird keys']['a.b']": {""},
+
+		// Slices
+		"$.loans[1:].id":   {"L2", "L3"},
+		"$.loans[:-1].id":  {"L1", "L2"},
+		"$.loans[::2].id":  {"L1", "L3"},
+		"$.loans[::-1].id": {"L3", "L2", "L1"},
+		"$.loans[5:9].id":  {},
+
+		// Filters
+		"$.loans[?(@.id == 'L2')].id":                       {"L2"},
+		`$.loans[?(@.id != "L2")].id`:                       {"L1", "L3"},
+		"$.loans[?(@.amount >= 500 && @.amount < 1000)].id": {"L1"},
+		"$.loans[?(@.amount <= 250 || @.id == 'L2')].id":    {"L2", "L3"},
+		"$..payments[?(@.paid == false)].id":                {"P3"},
+		"$..payments[?(@.note == 'it\\'s late')].id":        {"P5"},
+		"$..payments[?(@.amount > 'x')].id":                 {},
+		"$.loans[?(@.payments[0].amount == 100)].id":        {"L2"},
+		"$.loans[0].payments[*].amount[?(@ > 1)]":           {},
+		"$.loans[?(@.payments[?(@.amount > 200)])].id":      {"L1"},
+	} {
+		assert.Equal(want, ids(w.Path(expr)), expr)
+	}
+
+	// Values Of A Map In Key Order
+	found = w.Path("$.rates.*")
+	assert.Equal([]string{"key: rates | key: high", "key: rates | key: low"}, locations(found))
+	assert.Len(w.Path("$..*"), 40)
+
+	// Relative To The Instance
+	found = w.Key("loans").At(1).Path("$.payments[-1].id")
+	assert.Equal([]string{"key: loans | at: 1 | key: payments | at: 1 | key: id"}, locations(found))
+
+	// Invalid Expressions
+	for expr, failure := range map[string]string{
+		"$.loans[0":              "missing ] at position 8",
+		"$.":                     "missing name at position 2",
+		"$loans":                 "unexpected character at position 1",
+		"$.loans[x]":             "bad index: x",
+		"$.loans[1:2:0]":         "bad slice: 1:2:0",
+		"$.loans[?(@.id ==)]":    "missing operand after ==",
+		"$.loans[?(@.id &&)]":    "bad condition: @.id &&",
+		"$.loans[?(5)]":          "bad condition: 5",
+		"$.loans[?(@.id == 'x)]": "missing ] at position 8",
+	} {
+		found := w.Path(expr)
+		assert.Len(found, 1, expr)
+		assert.False(found[0].Ok(), expr)
+		assert.Equal("path: "+expr+" ("+failure+")", found[0].Failure(), expr)
+	}
+
+	// Failed Instances Pass Through
+	failed := w.Key("nope")
+	assert.Equal([]*W{failed}, failed.Path("$.loans"))
+}
@@ -0,0 +1,250 @@
+package jwalker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Stream iterates over the elements of an array within a JSON document
+// (or over the values of newline-delimited JSON) without decoding the
+// whole document. Only the current element is held in memory:
+//
+//	s := jwalker.NewStream(file, "$.exports.loans")
+//	for s.Next() {
+//		id, ok := s.W().KeyS("id")
+//	}
+//	if s.Err() != nil { ... }
+type Stream struct {
+	dec      *json.Decoder
+	steps    []step // Keys and indexes leading to the array
+	lines    bool   // Newline-delimited values rather than an array
+	started  bool   // The array has been found
+	done     bool   // No more elements
+	index    int    // Index of the next element
+	location string // Location of the array
+	current  *W
+	err      error
+}
+
+// NewStream returns a stream over the elements of the array at the
+// supplied path, which may only contain keys and indexes, e.g.
+// "$.exports.loans" or "$.pages[2].items" (the top-level array if the
+// path is "$" or empty). Each element's Location() includes the path.
+func NewStream(r io.Reader, path string) *Stream {
+	s := &Stream{dec: json.NewDecoder(r)}
+
+	steps, err := parsePath(path, "$")
+	if err != nil {
+		s.fail("path: " + path + " (" + err.Error() + ")")
+		return s
+	}
+
+	// Keys And Indexes Only (Counting From The Start)
+	valid := plain(steps)
+	for _, st := range steps {
+		if index, ok := st.sel.(indexSel); ok && index[0] < 0 {
+			valid = false
+		}
+	}
+	if !valid {
+		s.fail("path: " + path + " (only keys and indexes can be streamed)")
+		return s
+	}
+
+	s.steps = steps
+	return s
+}
+
+// NewLines returns a stream over newline-delimited JSON (one value per
+// line, also known as NDJSON or JSON Lines). The Nth value's Location()
+// is "at: N" (counting from zero).
+func NewLines(r io.Reader) *Stream {
+	return &Stream{dec: json.NewDecoder(r), lines: true, started: true}
+}
+
+// Next decodes the next element, returning false when there are no more
+// elements or an error occurred (see Err)
+func (s *Stream) Next() bool {
+	if s.done {
+		return false
+	}
+
+	// Find The Array
+	if !s.started {
+		if !s.seek() {
+			return false
+		}
+		s.started = true
+	}
+
+	// End Of The Array
+	if !s.lines && !s.dec.More() {
+		s.done = true
+		s.current = nil
+		return false
+	}
+
+	// Element
+	w := &W{location: s.location}
+	if err := s.dec.Decode(&w.obj); err != nil {
+		if err != io.EOF || !s.lines {
+			s.err = err
+		}
+		s.done = true
+		s.current = nil
+		return false
+	}
+
+	w.appendLocation("at: " + strconv.Itoa(s.index))
+	s.index++
+	s.current = w
+	return true
+}
+
+// W returns the current element (nil before the first call to Next and
+// after the last)
+func (s *Stream) W() *W {
+	return s.current
+}
+
+// Err returns the first error that occurred, other than reaching the end
+// of the input. A missing key or index, or a value that isn't an array,
+// is reported with the location where it occurred, e.g. "[location] =>
+// key: exports [failure] => key: loans (key does not exist)".
+func (s *Stream) Err() error {
+	return s.err
+}
+
+// Descends into the array at the path, reading past everything before it
+func (s *Stream) seek() bool {
+	for _, st := range s.steps {
+		switch sel := st.sel.(type) {
+
+		// Key
+		case keySel:
+			key := sel[0]
+			if !s.expect('{', "key: "+key, "not a map,") {
+				return false
+			}
+
+			for {
+				tok, err := s.dec.Token()
+				if err != nil {
+					return s.abort(err)
+				}
+				if tok == json.Delim('}') {
+					s.fail("key: " + key + " (key does not exist)")
+					return false
+				}
+				if tok == key {
+					break
+				}
+				if err := skip(s.dec); err != nil {
+					return s.abort(err)
+				}
+			}
+
+			s.appendLocation("key: " + key)
+
+		// Index
+		case indexSel:
+			i := sel[0]
+			if !s.expect('[', "at: "+strconv.Itoa(i), "not an array,") {
+				return false
+			}
+
+			for n := 0; n < i; n++ {
+				if !s.dec.More() {
+					s.fail(fmt.Sprintf("at: %d (out of range, size=%d)", i, n))
+					return false
+				}
+				if err := skip(s.dec); err != nil {
+					return s.abort(err)
+				}
+			}
+			if !s.dec.More() {
+				s.fail(fmt.Sprintf("at: %d (out of range, size=%d)", i, i))
+				return false
+			}
+
+			s.appendLocation("at: " + strconv.Itoa(i))
+		}
+	}
+
+	// The Array Itself
+	return s.expect('[', "stream", "not an array,")
+}
+
+// Reads the opening delimiter of the next value, failing with the
+// operation and reason if the value is of another type
+func (s *Stream) expect(delim json.Delim, op, reason string) bool {
+	tok, err := s.dec.Token()
+	if err != nil {
+		return s.abort(err)
+	}
+
+	if tok != delim {
+		s.fail(fmt.Sprintf("%s (%s rather it is of type %s)", op, reason, typeName(tok)))
+		return false
+	}
+	return true
+}
+
+// Records a failure at the current location (as reported by Trace)
+func (s *Stream) fail(failure string) {
+	s.err = errors.New((&W{location: s.location, failure: failure}).Trace())
+	s.done = true
+}
+
+// Records a decoding error
+func (s *Stream) abort(err error) bool {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	s.err = err
+	s.done = true
+	return false
+}
+
+// Appends to the location of the array
+func (s *Stream) appendLocation(l string) {
+	w := W{location: s.location}
+	w.appendLocation(l)
+	s.location = w.location
+}
+
+// Reads past the next value (without decoding it)
+func skip(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// Returns the type a token's value decodes to, as in the failures of Key and At
+func typeName(tok json.Token) string {
+	switch tok {
+	case json.Delim('{'):
+		return "map[string]interface {}"
+	case json.Delim('['):
+		return "[]interface {}"
+	}
+	return fmt.Sprintf("%T", tok)
+}
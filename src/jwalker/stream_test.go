@@ -0,0 +1,149 @@
+package jwalker
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns the elements of the stream
+func collect(s *Stream) []*W {
+	result := []*W{}
+	for s.Next() {
+		result = append(result, s.W())
+	}
+	return result
+}
+
+// Endless is a reader producing an array that never ends
+type endless struct {
+	n       int
+	pending string
+}
+
+func (e *endless) Read(p []byte) (int, error) {
+	if e.pending == "" {
+		e.pending = `{"n": ` + strconv.Itoa(e.n) + `},`
+		if e.n == 0 {
+			e.pending = `{"data": [` + e.pending
+		}
+		e.n++
+	}
+
+	n := copy(p, e.pending)
+	e.pending = e.pending[n:]
+	return n, nil
+}
+
+func TestStream(t *testing.T) {
+	assert := assert.New(t)
+
+	// Nested Array
+	file, err := os.Open("test/loans.json")
+	assert.Nil(err)
+	defer file.Close()
+
+	s := NewStream(file, "$.loans")
+	assert.Nil(s.W())
+	loans := collect(s)
+	assert.Nil(s.Err())
+	assert.Nil(s.W())
+	assert.False(s.Next())
+
+	assert.Len(loans, 3)
+	assert.Equal("key: loans | at: 2", loans[2].Location())
+	id, ok := loans[2].KeyS("id")
+	assert.True(ok)
+	assert.Equal("L3", id)
+
+	// Paths Work On Each Element
+	found := loans[1].Path("$.payments[-1].id")
+	assert.Equal([]string{"P5"}, ids(found))
+	assert.Equal([]string{"key: loans | at: 1 | key: payments | at: 1 | key: id"}, locations(found))
+
+	// Top-Level Array
+	elems := collect(NewStream(strings.NewReader(`[1, {"a": [2]}, "x"]`), "$"))
+	assert.Len(elems, 3)
+	assert.Equal("at: 0", elems[0].Location())
+	i, ok := elems[1].Key("a").AtI(0)
+	assert.True(ok)
+	assert.Equal(2, i)
+
+	elems = collect(NewStream(strings.NewReader(`[]`), ""))
+	assert.Len(elems, 0)
+
+	// Keys And Indexes (Skipping Whatever Comes Before)
+	doc := `{"skip": {"deep": [1, [2, {"x": 3}]]}, "pages": [{"items": [0]}, [], {"items": [5, 6]}], "after": "ignored"}`
+	s = NewStream(strings.NewReader(doc), "$.pages[2].items")
+	elems = collect(s)
+	assert.Nil(s.Err())
+	assert.Len(elems, 2)
+	assert.Equal("key: pages | at: 2 | key: items | at: 1", elems[1].Location())
+
+	s = NewStream(strings.NewReader(doc), `$['pages'][0]['items']`)
+	assert.Len(collect(s), 1)
+
+	// Failures
+	for path, text := range map[string]string{
+		"$.nope":            "[location] =>  [failure] => key: nope (key does not exist)",
+		"$.skip.deep[5]":    "[location] => key: skip | key: deep [failure] => at: 5 (out of range, size=2)",
+		"$.skip.deep[1][1]": "[location] => key: skip | key: deep | at: 1 | at: 1 [failure] => stream (not an array, rather it is of type map[string]interface {})",
+		"$.pages[1].a":      "[location] => key: pages | at: 1 [failure] => key: a (not a map, rather it is of type []interface {})",
+		"$.after":           "[location] => key: after [failure] => stream (not an array, rather it is of type string)",
+		"$.skip[0]":         "[location] => key: skip [failure] => at: 0 (not an array, rather it is of type map[string]interface {})",
+		"$..x":              "[location] =>  [failure] => path: $..x (only keys and indexes can be streamed)",
+		"$.pages[*]":        "[location] =>  [failure] => path: $.pages[*] (only keys and indexes can be streamed)",
+		"$.pages[-1]":       "[location] =>  [failure] => path: $.pages[-1] (only keys and indexes can be streamed)",
+		"$.pages[":          "[location] =>  [failure] => path: $.pages[ (missing ] at position 8)",
+	} {
+		s := NewStream(strings.NewReader(doc), path)
+		assert.False(s.Next(), path)
+		assert.EqualError(s.Err(), text, path)
+	}
+
+	// Bounded Memory (The Input Never Ends)
+	s = NewStream(&endless{}, "$.data")
+	for i := 0; i < 10000; i++ {
+		assert.True(s.Next())
+	}
+	n, ok := s.W().KeyI("n")
+	assert.True(ok)
+	assert.Equal(9999, n)
+	assert.Equal("key: data | at: 9999", s.W().Location())
+
+	// Decoding Errors
+	s = NewStream(strings.NewReader(`{"a": [1, 2`), "$.a")
+	assert.Len(collect(s), 2)
+	assert.NotNil(s.Err())
+
+	s = NewStream(strings.NewReader(`{"a": `), "$.a")
+	assert.False(s.Next())
+	assert.NotNil(s.Err())
+}
+
+func TestLines(t *testing.T) {
+	assert := assert.New(t)
+
+	input := `{"id": "P1", "amount": 50}
+{"id": "P2", "amount": 150}
+
+{"id": "P3", "amount": 300}
+`
+
+	s := NewLines(strings.NewReader(input))
+	lines := collect(s)
+	assert.Nil(s.Err())
+	assert.Len(lines, 3)
+	assert.Equal("at: 2", lines[2].Location())
+	amount, ok := lines[1].KeyI("amount")
+	assert.True(ok)
+	assert.Equal(150, amount)
+
+	// Errors
+	s = NewLines(strings.NewReader("{\"id\": 1}\n{\"id\": \n"))
+	assert.Len(collect(s), 1)
+	assert.NotNil(s.Err())
+}